@@ -0,0 +1,91 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+const (
+	APIVersion = "7.0"
+
+	cloudHost   = "dev.azure.com"
+	profileHost = "https://app.vssps.visualstudio.com"
+)
+
+func Get(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error while forming request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(":"+pat)))
+
+	// NOTE: An invalid PAT is answered with a redirect to the sign-in page rather than a 401, so the redirect must not
+	// be followed for it to be told apart from a successful response.
+	noRedirectClient := *client
+	noRedirectClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	response, err := noRedirectClient.Do(r)
+	if err != nil {
+		return fmt.Errorf("error while executing request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error while parsing response body: %w", err)
+	}
+	if isSignInResponse(response) {
+		return fmt.Errorf("authentication failed with response status %s, the PAT is invalid or has expired",
+			response.Status)
+	}
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected response status %s: %s", response.Status, string(body))
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(body, responseDTO)
+	if err != nil {
+		return fmt.Errorf("error while parsing response: %w", err)
+	}
+	return nil
+}
+
+// isSignInResponse reports whether the response sends the caller to the sign-in page instead of answering the API
+// call, either as a redirect or as a non JSON page.
+func isSignInResponse(response *http.Response) bool {
+	if response.StatusCode == http.StatusUnauthorized ||
+		(response.StatusCode >= http.StatusMultipleChoices && response.StatusCode < http.StatusBadRequest) {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	return response.StatusCode < http.StatusMultipleChoices && err == nil && mediaType == "text/html"
+}
+
+// IsCloud reports whether the host is Azure DevOps Services, where a single PAT can span several organizations.
+// Any other host is treated as the URL of a single Azure DevOps Server collection.
+func IsCloud(host string) bool {
+	parsedHost, err := url.Parse(host)
+	return err == nil && parsedHost.Host == cloudHost
+}
+
+// ProfileURL returns the URL of the Azure DevOps Services profile API.
+func ProfileURL() string {
+	return profileHost
+}
+
+// OrgURL returns the base URL of an organization. On Azure DevOps Server the host already points at the collection
+// and org is empty.
+func OrgURL(host string, org string) string {
+	if org == "" {
+		return host
+	}
+	return host + "/" + url.PathEscape(org)
+}
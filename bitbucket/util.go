@@ -0,0 +1,83 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func Get(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) error {
+	_, err := do(ctx, client, http.MethodGet, pat, url, responseDTO)
+	return err
+}
+
+// GetWithHeader behaves like Get and additionally returns the response headers.
+func GetWithHeader(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) (http.Header, error) {
+	return do(ctx, client, http.MethodGet, pat, url, responseDTO)
+}
+
+// CloudAPIURL returns the REST API base URL for a Bitbucket Cloud host. The public bitbucket.org site serves its
+// API from a separate subdomain, any other host is assumed to proxy the API under /2.0.
+func CloudAPIURL(host string) string {
+	parsedHost, err := url.Parse(host)
+	if err == nil && (parsedHost.Host == "bitbucket.org" || parsedHost.Host == "www.bitbucket.org") {
+		return "https://api.bitbucket.org/2.0"
+	}
+	return host + "/2.0"
+}
+
+// DataCenterAPIURL returns the REST API base URL for a Bitbucket Data Center host.
+func DataCenterAPIURL(host string) string {
+	return host + "/rest/api/latest"
+}
+
+func do(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	pat string,
+	url string,
+	responseDTO any,
+) (http.Header, error) {
+	r, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while forming request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", authorization(pat))
+
+	response, err := client.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response body: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected response status %s: %s", response.Status, string(body))
+	}
+	if len(body) == 0 {
+		return response.Header, nil
+	}
+	err = json.Unmarshal(body, responseDTO)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response: %w", err)
+	}
+	return response.Header, nil
+}
+
+// authorization builds the Authorization header for the PAT. Tokens of the form username:app-password are sent
+// with basic auth, anything else is treated as a bearer access token.
+func authorization(pat string) string {
+	if strings.Contains(pat, ":") {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(pat))
+	}
+	return "Bearer " + pat
+}
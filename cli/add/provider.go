@@ -2,17 +2,13 @@ package add
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/dhruv1397/prm/cli"
-	"github.com/dhruv1397/prm/clientbuilder"
 	"github.com/dhruv1397/prm/store"
 	"github.com/dhruv1397/prm/types"
-	"golang.org/x/term"
-	"net/url"
 	"os"
-	"strings"
-	"syscall"
 	"time"
 )
 
@@ -20,14 +16,18 @@ type providerCommand struct {
 	name         string
 	providerType string
 	host         string
-	pat          string
+	api          string
+	concurrency  int
+	patStdin     bool
+	patEnv       string
+	patCmd       string
 }
 
 func (c *providerCommand) run(*kingpin.ParseContext) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 
 	existingProviders, err := str.List(c.providerType, c.name)
 	if err != nil {
@@ -38,63 +38,38 @@ func (c *providerCommand) run(*kingpin.ParseContext) error {
 		return fmt.Errorf("SCM provider %s already exists", c.name)
 	}
 
-	host, err := url.Parse(c.host)
-	if err != nil {
-		return err
+	patSources := 0
+	for _, set := range []bool{c.patStdin, c.patEnv != "", c.patCmd != ""} {
+		if set {
+			patSources++
+		}
 	}
-
-	if host.Scheme == "" {
-		host.Scheme = "https"
+	if patSources > 1 {
+		return fmt.Errorf("--%s, --%s and --%s are mutually exclusive", cli.FlagPATStdin, cli.FlagPATEnv,
+			cli.FlagPATCmd)
 	}
 
-	pat := promptForSecret()
-
-	c.host = strings.TrimSuffix(host.String(), "/")
-	newProvider := &types.SCMProvider{
-		Type:    c.providerType,
-		Name:    c.name,
-		Host:    c.host,
-		User:    nil,
-		Repos:   nil,
-		Updated: time.Now().UnixMilli(),
-		Created: time.Now().UnixMilli(),
+	entry := &types.SCMProviderEntry{
+		Name:        c.name,
+		Type:        c.providerType,
+		Host:        c.host,
+		API:         c.api,
+		Concurrency: c.concurrency,
+		PATEnv:      c.patEnv,
+		PATCmd:      c.patCmd,
 	}
-
-	if c.providerType == "github" {
-		scmClient, err := clientbuilder.GetGithubSCMClient(ctx, pat)
-		if err != nil {
-			return err
-		}
-		gituhbUser, err := scmClient.GetUser(ctx)
-		if err != nil {
-			return err
-		}
-		gituhbUser.PAT = pat
-		newProvider.User = gituhbUser
-	} else if c.providerType == "harness" {
-		scmClient, err := clientbuilder.GetHarnessSCMClient(newProvider.Host, pat)
-		if err != nil {
-			return err
-		}
-		harnessUser, err := scmClient.GetUser(ctx)
-		if err != nil {
-			return err
-		}
-		newProvider.User = harnessUser
-		repos, err := scmClient.GetRepos(ctx)
+	if c.patStdin {
+		entry.PAT, err = cli.ReadSecret(os.Stdin)
 		if err != nil {
 			return err
 		}
-		newProvider.Repos = repos
-	} else {
-		return fmt.Errorf("unknown provider type: %s", c.providerType)
 	}
 
-	err = str.Create(*newProvider)
-	if err != nil {
-		return err
+	err = cli.CreateProvider(ctx, str, entry, true)
+	if errors.Is(err, cli.ErrNoTerminal) {
+		return fmt.Errorf("%w, use --%s, --%s or --%s instead", err, cli.FlagPATStdin, cli.FlagPATEnv, cli.FlagPATCmd)
 	}
-	return nil
+	return err
 }
 
 func registerProvider(app *kingpin.CmdClause) {
@@ -107,13 +82,14 @@ func registerProvider(app *kingpin.CmdClause) {
 	cmd.Flag(cli.FlagType, cli.FlagTypeHelpText).Short(cli.FlagTypeShort).Required().StringVar(&c.providerType)
 
 	cmd.Flag(cli.FlagHost, cli.FlagHostHelpText).Short(cli.FlagHostShort).Required().StringVar(&c.host)
-}
 
-func promptForSecret() string {
-	fmt.Println("Enter the PAT (Personal Access Token):")
-	patBytes, err := term.ReadPassword(syscall.Stdin)
-	if err != nil {
-		os.Exit(1)
-	}
-	return string(patBytes)
+	cmd.Flag(cli.FlagAPI, cli.FlagAPIHelpText).Default(cli.APIRest).EnumVar(&c.api, cli.APIRest, cli.APIGraphQL)
+
+	cmd.Flag(cli.FlagProviderConcurrency, cli.FlagProviderConcurrencyHelpText).IntVar(&c.concurrency)
+
+	cmd.Flag(cli.FlagPATStdin, cli.FlagPATStdinHelpText).BoolVar(&c.patStdin)
+
+	cmd.Flag(cli.FlagPATEnv, cli.FlagPATEnvHelpText).StringVar(&c.patEnv)
+
+	cmd.Flag(cli.FlagPATCmd, cli.FlagPATCmdHelpText).StringVar(&c.patCmd)
 }
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"fmt"
+	"io"
 	"os"
 )
 
@@ -10,49 +12,146 @@ const (
 	CommandRefresh = "refresh"
 	CommandList    = "list"
 	CommandPurge   = "purge"
+	CommandConfig  = "config"
+	CommandImport  = "import"
+	CommandExport  = "export"
+	CommandRestore = "restore"
+	CommandProfile = "profile"
 
 	CommandAddHelpText     = "Add a new SCM provider."
 	CommandRemoveHelpText  = "Remove a new SCM provider."
 	CommandRefreshHelpText = "Refresh fetched values eg repos, user-name, etc for all the SCM providers."
 	CommandListHelpText    = "List pull requests or SCM providers."
 	CommandPurgeHelpText   = "Purges all the data saved by the app."
+	CommandConfigHelpText  = "Manage the config file holding the SCM providers."
+	CommandImportHelpText  = "Import SCM providers from a file."
+	CommandExportHelpText  = "Export all the SCM providers and profiles to a file, eg to move them to another machine."
+	CommandRestoreHelpText = "Restore the SCM providers from a file written by export, validating each with its PAT."
+	CommandProfileHelpText = "Manage the profiles grouping SCM providers, eg work and oss."
 
 	SubcommandProvider  = "provider"
 	SubcommandProviders = "providers"
 	SubcommandPRs       = "prs"
+	SubcommandEncrypt   = "encrypt"
+	SubcommandDecrypt   = "decrypt"
+	SubcommandDoctor    = "doctor"
+	SubcommandCreate    = "create"
+	SubcommandUse       = "use"
+	SubcommandList      = "list"
+	SubcommandDelete    = "delete"
 
 	SubcommandAddProviderHelpText      = "Add an SCM provider."
 	SubcommandRemoveProviderHelpText   = "Remove an SCM provider."
 	SubcommandListProvidersHelpText    = "List SCM providers."
 	SubcommandRefreshProvidersHelpText = "Refresh all the SCM providers."
 	SubcommandPRsHelpText              = "List pull requests."
+	SubcommandImportProvidersHelpText  = "Add all the SCM providers listed in a file, validating each with its PAT."
+	SubcommandEncryptHelpText          = "Encrypt the config, PATs included, with a passphrase read from PRM_CONFIG_PASSPHRASE or prompted for."
+	SubcommandDoctorHelpText           = "Check the config for problems, eg unknown provider types or duplicate names, and fix what can be fixed."
+	SubcommandDecryptHelpText          = "Decrypt the config back to plaintext, moving the PATs to the keyring."
+	SubcommandCreateProfileHelpText    = "Create a profile of existing SCM providers."
+	SubcommandUseProfileHelpText       = "Make all the commands act on the SCM providers of the profile."
+	SubcommandListProfilesHelpText     = "List the profiles."
+	SubcommandDeleteProfileHelpText    = "Delete a profile, keeping its SCM providers."
 
 	ArgName         = "name"
 	ArgNameHelpText = "Name of the SCM provider."
 
+	ArgProfileHelpText = "Name of the profile."
+
 	FlagName   = "name"
 	FlagType   = "type"
 	FlagHost   = "host"
 	FlagState  = "state"
+	FlagRole   = "role"
 	FlagOutput = "output"
 	FlagForce  = "force"
+	FlagAPI    = "api"
+	FlagFile   = "file"
+	FlagPATs   = "pats"
+	FlagAll    = "all"
+	FlagWide   = "wide"
+	FlagFilter = "filter"
+
+	FlagVerbose     = "verbose"
+	FlagConcurrency = "concurrency"
+	FlagConfig      = "config"
+	FlagProfile     = "profile"
+
+	EnvConfig  = "PRM_CONFIG"
+	EnvProfile = "PRM_PROFILE"
+
+	FlagProviderConcurrency = "provider-concurrency"
+	FlagPATStdin            = "pat-stdin"
+	FlagPATEnv              = "pat-env"
+	FlagPATCmd              = "pat-cmd"
+	FlagProfileProvider     = "provider"
 
 	FlagNameShort   = 'n'
 	FlagTypeShort   = 't'
 	FlagHostShort   = 'h'
 	FlagStateShort  = 's'
+	FlagRoleShort   = 'r'
+	FlagWideShort   = 'w'
 	FlagOutputShort = 'o'
 	FlagOutputForce = 'f'
+	FlagFileShort   = 'f'
+
+	FlagNameHelpText       = "Name of the SCM provider."
+	FlagTypeHelpText       = "Type of the SCM provider:- [github/harness/gitlab/bitbucket-cloud/bitbucket-dc/gitea/azure/gerrit]."
+	FlagHostHelpText       = "Host URL of the SCM provider, eg https://github.com, https://app.harness.io, https://gitlab.com, https://bitbucket.org."
+	FlagStateHelpText      = "State of the pull request:- [open/merged/closed/all]."
+	FlagRoleHelpText       = "Your role in the pull request:- [author/reviewer/assignee/involved]. Not every SCM provider supports every role."
+	FlagOutputHelpText     = "Output format:- [table/json/yaml]."
+	FlagForceHelpText      = "Delete all the SCM providers without confirmation."
+	FlagFileHelpText       = "Path of the file listing the SCM providers, - for stdin."
+	FlagPATsHelpText       = "How to export the PATs stored by prm:- [redact/include/encrypt]. PATs read from env vars or commands are always exported as references."
+	FlagExportFileHelpText = "Path of the file to export the SCM providers to, - for stdout."
+	FlagAPIHelpText        = "API used to fetch pull requests, applicable only to github:- [rest/graphql]."
+	FlagAllHelpText        = "Act on all the SCM providers rather than those of a profile."
+	FlagWideHelpText       = "Also show the repo, branches, author, draft flag, labels and dates of the PRs in the table."
+	FlagFilterHelpText     = "Only list the PRs matching the expression, eg 'mergeable && approved >= 2 && !draft'."
+
+	FlagVerboseHelpText             = "Print the PRs found and fetched by GitHub searches, and the requests, retries and remaining rate limit per host."
+	FlagConcurrencyHelpText         = "Maximum number of requests in flight at once across all the SCM providers."
+	FlagConfigHelpText              = "Path of the config file holding the SCM providers, defaults to $XDG_CONFIG_HOME/prm/config.yaml."
+	FlagProfileHelpText             = "Act on the SCM providers of this profile instead of the one set with `prm profile use`."
+	FlagProviderConcurrencyHelpText = "Maximum number of requests in flight at once to this SCM provider, 0 for no limit other than the global one."
+	FlagPATStdinHelpText            = "Read the PAT from the first line of stdin instead of prompting for it."
+	FlagPATEnvHelpText              = "Read the PAT from this environment variable whenever it is needed instead of storing it, eg GITHUB_TOKEN."
+	FlagPATCmdHelpText              = "Run this command to print the PAT whenever it is needed instead of storing it, eg 'gh auth token'."
+	FlagProfileProviderHelpText     = "Name of an SCM provider to add to the profile, repeatable."
 
-	FlagNameHelpText   = "Name of the SCM provider."
-	FlagTypeHelpText   = "Type of the SCM provider:- [github/harness]."
-	FlagHostHelpText   = "Host URL of the SCM provider, eg https://github.com, https://app.harness.io."
-	FlagStateHelpText  = "State of the pull request:- [open/merged/closed/all]."
-	FlagOutputHelpText = "Output format:- [table/json/yaml]."
-	FlagForceHelpText  = "Delete all the SCM providers without confirmation."
+	APIRest    = "rest"
+	APIGraphQL = "graphql"
+
+	PATsRedact  = "redact"
+	PATsInclude = "include"
+	PATsEncrypt = "encrypt"
 )
 
 func GetArguments() []string {
 	args := os.Args[1:]
 	return args
 }
+
+// IsStdin reports whether the path of a --file flag stands for stdin or stdout.
+func IsStdin(path string) bool {
+	// NOTE: kingpin parses a lone - passed as a separate argument, eg -f -, as an empty value.
+	return path == "-" || path == ""
+}
+
+// ReadInput reads the whole file, or stdin when the path is -.
+func ReadInput(path string) ([]byte, error) {
+	var content []byte
+	var err error
+	if IsStdin(path) {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+)
+
+func Register(app *kingpin.Application) {
+	cmd := app.Command(cli.CommandConfig, cli.CommandConfigHelpText)
+	registerEncrypt(cmd)
+	registerDecrypt(cmd)
+	registerDoctor(cmd)
+}
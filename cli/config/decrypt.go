@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+)
+
+type decryptCommand struct {
+}
+
+func (c *decryptCommand) run(*kingpin.ParseContext) error {
+	err := store.DecryptConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Config decrypted.")
+	return nil
+}
+
+func registerDecrypt(app *kingpin.CmdClause) {
+	c := &decryptCommand{}
+
+	app.Command(cli.SubcommandDecrypt, cli.SubcommandDecryptHelpText).Action(c.run)
+}
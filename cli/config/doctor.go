@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+)
+
+type doctorCommand struct {
+}
+
+func (c *doctorCommand) run(*kingpin.ParseContext) error {
+	str := store.NewSCMProvider()
+	report, err := str.Doctor()
+	if err != nil {
+		return err
+	}
+
+	for _, fixed := range report.Fixed {
+		fmt.Printf("Fixed: %s\n", fixed)
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("Problem: %s\n", problem)
+	}
+
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("found %d problems which need to be fixed by hand", len(report.Problems))
+	}
+	if len(report.Fixed) == 0 {
+		fmt.Println("No problems found!")
+	}
+	return nil
+}
+
+func registerDoctor(app *kingpin.CmdClause) {
+	c := &doctorCommand{}
+
+	app.Command(cli.SubcommandDoctor, cli.SubcommandDoctorHelpText).Action(c.run)
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+)
+
+type encryptCommand struct {
+}
+
+func (c *encryptCommand) run(*kingpin.ParseContext) error {
+	err := store.EncryptConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Config encrypted, set %s or enter the passphrase when prompted to use prm.\n", store.PassphraseEnv)
+	return nil
+}
+
+func registerEncrypt(app *kingpin.CmdClause) {
+	c := &encryptCommand{}
+
+	app.Command(cli.SubcommandEncrypt, cli.SubcommandEncryptHelpText).Action(c.run)
+}
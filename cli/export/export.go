@@ -0,0 +1,109 @@
+package export
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/clientbuilder"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/types"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+	"time"
+)
+
+// Version is the version of the export format, bumped whenever restoring an older export needs special handling.
+const Version = 1
+
+// PassphraseEnv is the environment variable holding the passphrase of an encrypted export, prompted for otherwise.
+const PassphraseEnv = "PRM_EXPORT_PASSPHRASE"
+
+type command struct {
+	file string
+	pats string
+}
+
+func (c *command) run(*kingpin.ParseContext) error {
+	str := store.NewSCMProvider()
+
+	// NOTE: The whole config is exported whichever profile is in use, so that nothing is lost when restoring it after
+	// a purge.
+	providers, profiles, currentProfile, err := str.ListAll()
+	if err != nil {
+		return err
+	}
+
+	export := &types.SCMProviderExport{
+		Version:        Version,
+		Exported:       time.Now().UnixMilli(),
+		Providers:      make([]*types.SCMProviderEntry, 0, len(providers)),
+		Profiles:       profiles,
+		CurrentProfile: currentProfile,
+	}
+	for _, provider := range providers {
+		export.Providers = append(export.Providers, c.toEntry(provider))
+	}
+
+	data, err := yaml.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("error serialising SCM providers: %w", err)
+	}
+
+	if c.pats == cli.PATsEncrypt {
+		passphrase, err := encryption.ReadPassphrase(PassphraseEnv, "Enter a passphrase to encrypt the export with:",
+			true)
+		if err != nil {
+			return err
+		}
+		data, err = encryption.Encrypt(data, passphrase)
+		if err != nil {
+			return fmt.Errorf("error encrypting SCM providers: %w", err)
+		}
+	}
+
+	if cli.IsStdin(c.file) {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = os.WriteFile(c.file, data, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing SCM providers to %s: %w", c.file, err)
+	}
+	fmt.Printf("Exported %d SCM providers and %d profiles to %s\n", len(export.Providers), len(export.Profiles), c.file)
+	return nil
+}
+
+func (c *command) toEntry(provider *types.SCMProvider) *types.SCMProviderEntry {
+	entry := &types.SCMProviderEntry{
+		Name:        provider.Name,
+		Type:        provider.Type,
+		Host:        provider.Host,
+		API:         provider.API,
+		Concurrency: provider.Concurrency,
+	}
+	if provider.User == nil {
+		return entry
+	}
+
+	if strings.HasPrefix(provider.User.PATRef, clientbuilder.PATRefEnvPrefix) {
+		entry.PATEnv = strings.TrimPrefix(provider.User.PATRef, clientbuilder.PATRefEnvPrefix)
+	} else if strings.HasPrefix(provider.User.PATRef, clientbuilder.PATRefCmdPrefix) {
+		entry.PATCmd = strings.TrimPrefix(provider.User.PATRef, clientbuilder.PATRefCmdPrefix)
+	} else if c.pats != cli.PATsRedact {
+		entry.PAT = provider.User.PAT
+	}
+	return entry
+}
+
+func Register(app *kingpin.Application) {
+	c := &command{}
+
+	cmd := app.Command(cli.CommandExport, cli.CommandExportHelpText).Action(c.run)
+
+	cmd.Flag(cli.FlagFile, cli.FlagExportFileHelpText).Short(cli.FlagFileShort).Default("-").StringVar(&c.file)
+
+	cmd.Flag(cli.FlagPATs, cli.FlagPATsHelpText).Default(cli.PATsRedact).
+		EnumVar(&c.pats, cli.PATsRedact, cli.PATsInclude, cli.PATsEncrypt)
+}
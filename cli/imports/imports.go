@@ -0,0 +1,11 @@
+package imports
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+)
+
+func Register(app *kingpin.Application) {
+	cmd := app.Command(cli.CommandImport, cli.CommandImportHelpText)
+	registerProviders(cmd)
+}
@@ -0,0 +1,63 @@
+package imports
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/types"
+	"gopkg.in/yaml.v3"
+)
+
+type providersCommand struct {
+	file string
+}
+
+// run adds the providers listed in the file, eg
+//
+//	providers:
+//	  - name: my-github
+//	    type: github
+//	    host: https://github.com
+//	    pat_env: GITHUB_TOKEN
+func (c *providersCommand) run(*kingpin.ParseContext) error {
+	providersFile, err := c.readFile()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*types.SCMProviderEntry, 0, len(providersFile.Providers))
+	for _, entry := range providersFile.Providers {
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("No providers to import!")
+		return nil
+	}
+
+	return cli.CreateProviders(store.NewSCMProvider(), entries, false)
+}
+
+func (c *providersCommand) readFile() (*types.SCMProviderExport, error) {
+	content, err := cli.ReadInput(c.file)
+	if err != nil {
+		return nil, err
+	}
+
+	providersFile := &types.SCMProviderExport{}
+	err = yaml.Unmarshal(content, providersFile)
+	if err != nil {
+		return nil, fmt.Errorf("error deserialising providers file %s: %w", c.file, err)
+	}
+	return providersFile, nil
+}
+
+func registerProviders(app *kingpin.CmdClause) {
+	c := &providersCommand{}
+
+	cmd := app.Command(cli.SubcommandProviders, cli.SubcommandImportProvidersHelpText).Action(c.run)
+
+	cmd.Flag(cli.FlagFile, cli.FlagFileHelpText).Short(cli.FlagFileShort).Required().StringVar(&c.file)
+}
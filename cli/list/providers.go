@@ -13,7 +13,7 @@ type providersCommand struct {
 }
 
 func (c *providersCommand) run(*kingpin.ParseContext) error {
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 	providers, err := str.List(c.providerType, c.providerName)
 	if err != nil {
 		return fmt.Errorf("failed to list providers: %w", err)
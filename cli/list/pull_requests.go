@@ -7,8 +7,10 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/dhruv1397/prm/cli"
 	"github.com/dhruv1397/prm/clientbuilder"
+	"github.com/dhruv1397/prm/filter"
 	"github.com/dhruv1397/prm/prclient"
 	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/transport"
 	"github.com/dhruv1397/prm/types"
 	"github.com/dhruv1397/prm/util"
 	"gopkg.in/yaml.v3"
@@ -31,25 +33,87 @@ const (
 	colWidthApproved         = 17
 	colWidthCommented        = 17
 	colWidthRequestedChanges = 17
+	colWidthPendingReviewers = 17
+	colWidthMyReview         = 17
+	colWidthChecks           = 17
+	colWidthRepo             = 17
+	colWidthBranches         = 17
+	colWidthAuthor           = 10
+	colWidthDraft            = 10
+	colWidthLabels           = 17
+	colWidthCreated          = 10
+	colWidthUpdated          = 10
 	colWidthURL              = 34
-	separatorLength          = 31 + colWidthSerialNumber + colWidthTitle + colWidthPRNumber +
-		colWidthSCMName + colWidthState + colWidthMergeable + colWidthApproved +
-		colWidthCommented + colWidthRequestedChanges + colWidthURL
-	spacingPattern = "| %-4s | %-34s | %-10s | %-10s | %-10s | %-10s | %-17s | %-17s | %-17s | %-34s |\n"
+
+	dateLayout = "2006-01-02"
 )
 
+// column of the PR table, cells returns the wrapped text of the PR in the column.
+type column struct {
+	header string
+	width  int
+	cells  func(pr *types.PrintablePullRequest) []string
+}
+
+var columns = []column{
+	{"Title", colWidthTitle, func(pr *types.PrintablePullRequest) []string { return pr.Title }},
+	{"PR Number", colWidthPRNumber, func(pr *types.PrintablePullRequest) []string { return pr.Number }},
+	{"SCM Name", colWidthSCMName, func(pr *types.PrintablePullRequest) []string { return pr.SCMProviderName }},
+	{"State", colWidthState, func(pr *types.PrintablePullRequest) []string { return pr.State }},
+	{"Mergeable", colWidthMergeable, func(pr *types.PrintablePullRequest) []string { return pr.Mergeable }},
+	{"Approved", colWidthApproved, func(pr *types.PrintablePullRequest) []string { return pr.Approved }},
+	{"Commented", colWidthCommented, func(pr *types.PrintablePullRequest) []string { return pr.Commented }},
+	{"Requested Changes", colWidthRequestedChanges,
+		func(pr *types.PrintablePullRequest) []string { return pr.RequestedChanges }},
+	{"Pending Reviewers", colWidthPendingReviewers,
+		func(pr *types.PrintablePullRequest) []string { return pr.PendingReviewers }},
+	{"My Review", colWidthMyReview, func(pr *types.PrintablePullRequest) []string { return pr.MyReview }},
+	{"Checks", colWidthChecks, func(pr *types.PrintablePullRequest) []string { return pr.Checks }},
+}
+
+// wideColumns are only shown with --wide, between the other columns and the URL.
+var wideColumns = []column{
+	{"Repo", colWidthRepo, func(pr *types.PrintablePullRequest) []string { return pr.Repo }},
+	{"Branches", colWidthBranches, func(pr *types.PrintablePullRequest) []string { return pr.Branches }},
+	{"Author", colWidthAuthor, func(pr *types.PrintablePullRequest) []string { return pr.Author }},
+	{"Draft", colWidthDraft, func(pr *types.PrintablePullRequest) []string { return pr.Draft }},
+	{"Labels", colWidthLabels, func(pr *types.PrintablePullRequest) []string { return pr.Labels }},
+	{"Created", colWidthCreated, func(pr *types.PrintablePullRequest) []string { return pr.Created }},
+	{"Updated", colWidthUpdated, func(pr *types.PrintablePullRequest) []string { return pr.Updated }},
+}
+
+var urlColumn = column{"URL", colWidthURL, func(pr *types.PrintablePullRequest) []string { return pr.URL }}
+
+var roles = []string{prclient.RoleAuthor, prclient.RoleReviewer, prclient.RoleAssignee, prclient.RoleInvolved}
+
 type prsCommand struct {
 	state        string
+	role         string
 	providerType string
 	providerName string
 	output       string
+	wide         bool
+	expression   string
+	filter       *filter.Filter
 }
 
 func (c *prsCommand) run(*kingpin.ParseContext) error {
+	if !slices.Contains(roles, c.role) {
+		return fmt.Errorf("unknown role: %s", c.role)
+	}
+
+	if c.expression != "" {
+		var err error
+		c.filter, err = filter.Parse(c.expression)
+		if err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 	providers, err := str.List(c.providerType, c.providerName)
 	if err != nil {
 		return fmt.Errorf("failed to list providers: %w", err)
@@ -72,28 +136,35 @@ func (c *prsCommand) helper(ctx context.Context, providers []*types.SCMProvider)
 	var prMutex sync.Mutex
 	var errMutex sync.Mutex
 
-	for _, provider := range providers {
-		wg.Add(1)
-		go func(provider *types.SCMProvider) {
-			defer wg.Done()
-
-			prClient, err := c.getPRClient(ctx, provider)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			prs, err := prClient.GetPullRequests(ctx, c.state, ConvertToPrintable)
-			if err != nil {
+	// NOTE: The SCM providers are started in the background, no more than the global concurrency at once, so that their
+	// results are collected meanwhile.
+	limiter := transport.NewLimiter(clientbuilder.GlobalConcurrency())
+	go func() {
+		for _, provider := range providers {
+			if err := limiter.Acquire(ctx); err != nil {
 				errCh <- err
-				return
+				break
 			}
-
-			respCh <- prs
-		}(provider)
-	}
-
-	go func() {
+			wg.Add(1)
+			go func(provider *types.SCMProvider) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prClient, err := c.getPRClient(ctx, provider)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				prs, err := prClient.GetPullRequests(ctx, c.state, c.role, ConvertToPrintable)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prs
+			}(provider)
+		}
 		wg.Wait()
 		close(respCh)
 		close(errCh)
@@ -120,6 +191,12 @@ func (c *prsCommand) helper(ctx context.Context, providers []*types.SCMProvider)
 		}
 	}
 
+	if c.filter != nil {
+		allPRs = slices.DeleteFunc(allPRs, func(pr *types.PullRequestResponse) bool {
+			return !c.filter.Match(pr.PR)
+		})
+	}
+
 	if len(allPRs) > 0 {
 		if c.output == "json" {
 			rawPRs := getRawPRs(allPRs)
@@ -138,7 +215,7 @@ func (c *prsCommand) helper(ctx context.Context, providers []*types.SCMProvider)
 			}
 			fmt.Println(string(yamlOutput))
 		} else {
-			printPullRequests(getPrintablePRs(allPRs))
+			printPullRequests(getPrintablePRs(allPRs), c.wide)
 		}
 	} else {
 		fmt.Println("No PRs found!")
@@ -153,9 +230,24 @@ func (c *prsCommand) helper(ctx context.Context, providers []*types.SCMProvider)
 
 func (c *prsCommand) getPRClient(ctx context.Context, provider *types.SCMProvider) (prclient.PRClient, error) {
 	if provider.Type == "github" {
-		return clientbuilder.GetGithubPRClient(ctx, provider.User, provider.Name)
+		if provider.API == cli.APIGraphQL {
+			return clientbuilder.GetGithubGraphQLPRClient(ctx, provider)
+		}
+		return clientbuilder.GetGithubPRClient(ctx, provider)
 	} else if provider.Type == "harness" {
-		return clientbuilder.GetHarnessPRClient(provider.Host, provider.User, provider.Repos, provider.Name)
+		return clientbuilder.GetHarnessPRClient(provider)
+	} else if provider.Type == "gitlab" {
+		return clientbuilder.GetGitlabPRClient(provider)
+	} else if provider.Type == "bitbucket-cloud" {
+		return clientbuilder.GetBitbucketCloudPRClient(provider)
+	} else if provider.Type == "bitbucket-dc" {
+		return clientbuilder.GetBitbucketDCPRClient(provider)
+	} else if provider.Type == "gitea" {
+		return clientbuilder.GetGiteaPRClient(provider)
+	} else if provider.Type == "azure" {
+		return clientbuilder.GetAzurePRClient(provider)
+	} else if provider.Type == "gerrit" {
+		return clientbuilder.GetGerritPRClient(provider)
 	} else {
 		return nil, fmt.Errorf("unknown provider type: %s", provider.Type)
 	}
@@ -184,79 +276,127 @@ func registerPRs(app *kingpin.CmdClause) {
 
 	cmd.Flag(cli.FlagState, cli.FlagStateHelpText).Short(cli.FlagStateShort).Default("open").StringVar(&c.state)
 
+	cmd.Flag(cli.FlagRole, cli.FlagRoleHelpText).Short(cli.FlagRoleShort).Default(prclient.RoleAuthor).
+		StringVar(&c.role)
+
 	cmd.Flag(cli.FlagType, cli.FlagTypeHelpText).Short(cli.FlagTypeShort).StringVar(&c.providerType)
 
 	cmd.Flag(cli.FlagName, cli.FlagNameHelpText).Short(cli.FlagNameShort).StringVar(&c.providerName)
 
 	cmd.Flag(cli.FlagOutput, cli.FlagOutputHelpText).Short(cli.FlagOutputShort).Default("table").StringVar(&c.output)
+
+	cmd.Flag(cli.FlagWide, cli.FlagWideHelpText).Short(cli.FlagWideShort).BoolVar(&c.wide)
+
+	cmd.Flag(cli.FlagFilter, cli.FlagFilterHelpText).StringVar(&c.expression)
 }
 
 func ConvertToPrintable(pr *types.PullRequest) *types.PrintablePullRequest {
-	wrappedTitle := wrapText(pr.Title, colWidthTitle)
-	wrappedPRNumber := wrapText(strconv.Itoa(pr.Number), colWidthPRNumber)
-	wrappedSCMName := wrapText(pr.SCMProviderName, colWidthSCMName)
-	wrappedState := wrapText(pr.State, colWidthState)
-	wrappedMergeable := wrapText(pr.Mergeable, colWidthMergeable)
-	wrappedApproved := wrapTextSlice(pr.Approved, colWidthApproved)
-	wrappedCommented := wrapTextSlice(pr.Commented, colWidthCommented)
-	wrappedRequestedChanges := wrapTextSlice(pr.RequestedChanges, colWidthRequestedChanges)
-	wrappedURL := wrapText(pr.URL, colWidthURL)
-
-	maxRows := max(
-		len(wrappedTitle),
-		len(wrappedPRNumber),
-		len(wrappedSCMName),
-		len(wrappedState),
-		len(wrappedMergeable),
-		len(wrappedApproved),
-		len(wrappedCommented),
-		len(wrappedRequestedChanges),
-		len(wrappedURL),
-	)
+	branches := ""
+	if pr.SourceBranch != "" || pr.TargetBranch != "" {
+		branches = fmt.Sprintf("%s -> %s", pr.SourceBranch, pr.TargetBranch)
+	}
+
 	return &types.PrintablePullRequest{
 		NumberRaw:          pr.Number,
 		SCMProviderTypeRaw: pr.SCMProviderType,
 		SCMProviderNameRaw: pr.SCMProviderName,
-		Title:              wrappedTitle,
-		Number:             wrappedPRNumber,
-		SCMProviderName:    wrappedSCMName,
-		State:              wrappedState,
-		Mergeable:          wrappedMergeable,
-		Approved:           wrappedApproved,
-		Commented:          wrappedCommented,
-		RequestedChanges:   wrappedRequestedChanges,
-		URL:                wrappedURL,
-		MaxRows:            maxRows,
+		Title:              wrapText(pr.Title, colWidthTitle),
+		Number:             wrapText(strconv.Itoa(pr.Number), colWidthPRNumber),
+		SCMProviderName:    wrapText(pr.SCMProviderName, colWidthSCMName),
+		State:              wrapText(pr.State, colWidthState),
+		Mergeable:          wrapText(pr.Mergeable, colWidthMergeable),
+		Approved:           wrapTextSlice(pr.Approved, colWidthApproved),
+		Commented:          wrapTextSlice(pr.Commented, colWidthCommented),
+		RequestedChanges:   wrapTextSlice(pr.RequestedChanges, colWidthRequestedChanges),
+		PendingReviewers:   wrapTextSlice(pr.PendingReviewers, colWidthPendingReviewers),
+		MyReview:           wrapText(pr.MyReview, colWidthMyReview),
+		Checks:             wrapText(formatChecks(pr.Checks), colWidthChecks),
+		Repo:               wrapText(pr.Repo, colWidthRepo),
+		Branches:           wrapText(branches, colWidthBranches),
+		Author:             wrapText(pr.Author, colWidthAuthor),
+		Draft:              wrapText(strconv.FormatBool(pr.Draft), colWidthDraft),
+		Labels:             wrapTextSlice(pr.Labels, colWidthLabels),
+		Created:            wrapText(formatDate(pr.Created), colWidthCreated),
+		Updated:            wrapText(formatDate(pr.Updated), colWidthUpdated),
+		URL:                wrapText(pr.URL, colWidthURL),
+	}
+}
+
+// formatDate formats the unix timestamp in milliseconds as a date, or - when it is unknown.
+func formatDate(timestamp int64) string {
+	if timestamp == 0 {
+		return "-"
+	}
+	return time.UnixMilli(timestamp).Format(dateLayout)
+}
+
+// formatChecks sums up the checks as eg "3 passing, 1 failing (lint), 2 pending", or - when they are unknown.
+func formatChecks(checks *types.ChecksSummary) string {
+	if checks == nil {
+		return "-"
+	}
+	var parts []string
+	if checks.Passing > 0 {
+		parts = append(parts, fmt.Sprintf("%d passing", checks.Passing))
+	}
+	if checks.Failing > 0 {
+		parts = append(parts, fmt.Sprintf("%d failing (%s)", checks.Failing, strings.Join(checks.FailingChecks, ", ")))
+	}
+	if checks.Pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", checks.Pending))
 	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
 }
 
-func printPullRequests(prs []*types.PrintablePullRequest) {
+func printPullRequests(prs []*types.PrintablePullRequest, wide bool) {
+	tableColumns := slices.Clone(columns)
+	if wide {
+		tableColumns = append(tableColumns, wideColumns...)
+	}
+	tableColumns = append(tableColumns, urlColumn)
+
+	separatorLength := colWidthSerialNumber + 3*(len(tableColumns)+1) + 1
+	headers := []string{"#"}
+	for _, col := range tableColumns {
+		separatorLength += col.width
+		headers = append(headers, col.header)
+	}
+
 	printSeparator(separatorLength)
-	fmt.Printf(spacingPattern, "#", "Title", "PR Number", "SCM Name", "State", "Mergeable", "Approved", "Commented",
-		"Requested Changes", "URL")
+	printRow(tableColumns, headers)
 	printSeparator(separatorLength)
 
 	slices.SortFunc(prs, types.ComparePrintablePullRequest)
 
 	for index, pr := range prs {
-		for i := 0; i < pr.MaxRows; i++ {
-			fmt.Printf(spacingPattern,
-				getSrNumberElement(index, i),
-				getListElement(pr.Title, i),
-				getListElement(pr.Number, i),
-				getListElement(pr.SCMProviderName, i),
-				getListElement(pr.State, i),
-				getListElement(pr.Mergeable, i),
-				getListElement(pr.Approved, i),
-				getListElement(pr.Commented, i),
-				getListElement(pr.RequestedChanges, i),
-				getListElement(pr.URL, i),
-			)
+		maxRows := 0
+		for _, col := range tableColumns {
+			maxRows = max(maxRows, len(col.cells(pr)))
+		}
+		for i := 0; i < maxRows; i++ {
+			row := []string{getSrNumberElement(index, i)}
+			for _, col := range tableColumns {
+				row = append(row, getListElement(col.cells(pr), i))
+			}
+			printRow(tableColumns, row)
 		}
 		printSeparator(separatorLength)
 	}
 }
 
+// printRow prints the serial number column followed by the cells of the columns.
+func printRow(tableColumns []column, cells []string) {
+	var row strings.Builder
+	row.WriteString(fmt.Sprintf("| %-*s |", colWidthSerialNumber, cells[0]))
+	for i, col := range tableColumns {
+		row.WriteString(fmt.Sprintf(" %-*s |", col.width, cells[i+1]))
+	}
+	fmt.Println(row.String())
+}
+
 func printSeparator(length int) {
 	fmt.Println(strings.Repeat("-", length))
 }
@@ -279,16 +419,6 @@ func wrapTextSlice(text []string, maxWidth int) []string {
 	return wrapText(strings.Join(text, ", "), maxWidth)
 }
 
-func max(values ...int) int {
-	maxValue := values[0]
-	for _, value := range values {
-		if value > maxValue {
-			maxValue = value
-		}
-	}
-	return maxValue
-}
-
 func getListElement(text []string, index int) string {
 	if index >= len(text) {
 		return ""
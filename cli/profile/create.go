@@ -0,0 +1,34 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/types"
+)
+
+type createCommand struct {
+	name      string
+	providers []string
+}
+
+func (c *createCommand) run(*kingpin.ParseContext) error {
+	str := store.NewSCMProvider()
+	err := str.CreateProfile(types.Profile{Name: c.name, Providers: c.providers})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created profile %s\n", c.name)
+	return nil
+}
+
+func registerCreate(app *kingpin.CmdClause) {
+	c := &createCommand{}
+
+	cmd := app.Command(cli.SubcommandCreate, cli.SubcommandCreateProfileHelpText).Action(c.run)
+
+	cmd.Arg(cli.ArgName, cli.ArgProfileHelpText).Required().StringVar(&c.name)
+
+	cmd.Flag(cli.FlagProfileProvider, cli.FlagProfileProviderHelpText).StringsVar(&c.providers)
+}
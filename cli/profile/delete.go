@@ -0,0 +1,30 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+)
+
+type deleteCommand struct {
+	name string
+}
+
+func (c *deleteCommand) run(*kingpin.ParseContext) error {
+	str := store.NewSCMProvider()
+	err := str.DeleteProfile(c.name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted profile %s\n", c.name)
+	return nil
+}
+
+func registerDelete(app *kingpin.CmdClause) {
+	c := &deleteCommand{}
+
+	cmd := app.Command(cli.SubcommandDelete, cli.SubcommandDeleteProfileHelpText).Action(c.run)
+
+	cmd.Arg(cli.ArgName, cli.ArgProfileHelpText).Required().StringVar(&c.name)
+}
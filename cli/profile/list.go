@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+	"strings"
+)
+
+type listCommand struct {
+}
+
+func (c *listCommand) run(*kingpin.ParseContext) error {
+	str := store.NewSCMProvider()
+	profiles, current, err := str.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %w", err)
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles found!")
+		return nil
+	}
+	fmt.Println(fmt.Sprintf("%-4s\t%-10s\t%-7s\t%s", "#", "Name", "Current", "Providers"))
+	for i, profile := range profiles {
+		marker := ""
+		if profile.Name == current {
+			marker = "*"
+		}
+		fmt.Println(fmt.Sprintf("%-4d\t%-10s\t%-7s\t%s", i, profile.Name, marker,
+			strings.Join(profile.Providers, ", ")))
+	}
+	return nil
+}
+
+func registerList(app *kingpin.CmdClause) {
+	c := &listCommand{}
+
+	app.Command(cli.SubcommandList, cli.SubcommandListProfilesHelpText).Action(c.run)
+}
@@ -0,0 +1,14 @@
+package profile
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+)
+
+func Register(app *kingpin.Application) {
+	cmd := app.Command(cli.CommandProfile, cli.CommandProfileHelpText)
+	registerCreate(cmd)
+	registerUse(cmd)
+	registerList(cmd)
+	registerDelete(cmd)
+}
@@ -0,0 +1,41 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/store"
+)
+
+type useCommand struct {
+	name string
+	all  bool
+}
+
+func (c *useCommand) run(*kingpin.ParseContext) error {
+	if c.all == (c.name != "") {
+		return fmt.Errorf("exactly one of a profile name and --%s must be given", cli.FlagAll)
+	}
+
+	str := store.NewSCMProvider()
+	err := str.UseProfile(c.name)
+	if err != nil {
+		return err
+	}
+	if c.all {
+		fmt.Println("Using all the SCM providers")
+	} else {
+		fmt.Printf("Using profile %s\n", c.name)
+	}
+	return nil
+}
+
+func registerUse(app *kingpin.CmdClause) {
+	c := &useCommand{}
+
+	cmd := app.Command(cli.SubcommandUse, cli.SubcommandUseProfileHelpText).Action(c.run)
+
+	cmd.Arg(cli.ArgName, cli.ArgProfileHelpText).StringVar(&c.name)
+
+	cmd.Flag(cli.FlagAll, cli.FlagAllHelpText).BoolVar(&c.all)
+}
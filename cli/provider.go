@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dhruv1397/prm/clientbuilder"
+	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/types"
+	"golang.org/x/term"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// NormaliseHost defaults the scheme of the host to https and strips its trailing slash.
+func NormaliseHost(rawHost string) (string, error) {
+	host, err := url.Parse(rawHost)
+	if err != nil {
+		return "", err
+	}
+
+	if host.Scheme == "" {
+		host.Scheme = "https"
+	}
+
+	return strings.TrimSuffix(host.String(), "/"), nil
+}
+
+// ValidateProvider checks the settings of a new SCM provider before any request is made to it.
+func ValidateProvider(provider *types.SCMProvider) error {
+	if provider.Name == "" {
+		return fmt.Errorf("SCM provider name must not be empty")
+	}
+	if provider.Host == "" {
+		return fmt.Errorf("SCM provider host must not be empty")
+	}
+	if provider.API != "" && provider.API != APIRest && provider.API != APIGraphQL {
+		return fmt.Errorf("unknown api: %s", provider.API)
+	}
+	if provider.API != "" && provider.API != APIRest && provider.Type != "github" {
+		return fmt.Errorf("api %s is only supported for github providers", provider.API)
+	}
+	if provider.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", provider.Concurrency)
+	}
+	return nil
+}
+
+// FetchProviderDetails fetches the user of the PAT, and the repos it can access for providers whose PRs are listed
+// per repo, and sets them on the provider. The PAT is kept on the user.
+func FetchProviderDetails(ctx context.Context, provider *types.SCMProvider, pat string) error {
+	var user *types.User
+	var repos []*types.Repo
+	if provider.Type == "github" {
+		scmClient, err := clientbuilder.GetGithubSCMClient(ctx, provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "harness" {
+		scmClient, err := clientbuilder.GetHarnessSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+		repos, err = scmClient.GetRepos(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "gitlab" {
+		scmClient, err := clientbuilder.GetGitlabSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "bitbucket-cloud" {
+		scmClient, err := clientbuilder.GetBitbucketCloudSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "bitbucket-dc" {
+		scmClient, err := clientbuilder.GetBitbucketDCSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "gitea" {
+		scmClient, err := clientbuilder.GetGiteaSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "azure" {
+		scmClient, err := clientbuilder.GetAzureSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+		repos, err = scmClient.GetRepos(ctx)
+		if err != nil {
+			return err
+		}
+	} else if provider.Type == "gerrit" {
+		scmClient, err := clientbuilder.GetGerritSCMClient(provider, pat)
+		if err != nil {
+			return err
+		}
+		user, err = scmClient.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("unknown provider type: %s", provider.Type)
+	}
+
+	user.PAT = pat
+	provider.User = user
+	provider.Repos = repos
+	return nil
+}
+
+// CreateProviders creates all the providers of the entries, reporting which could not be created without stopping at
+// the first one that fails.
+func CreateProviders(str store.SCMProvider, entries []*types.SCMProviderEntry, promptForPAT bool) error {
+	existingProviders, err := str.List("", "")
+	if err != nil {
+		return err
+	}
+	// NOTE: Names are checked upfront, which also catches duplicates within the entries, to avoid validating PATs of
+	// providers that cannot be created anyway.
+	takenNames := map[string]bool{}
+	for _, provider := range existingProviders {
+		takenNames[provider.Name] = true
+	}
+
+	failed := 0
+	for _, entry := range entries {
+		if takenNames[entry.Name] {
+			err = fmt.Errorf("SCM provider %s already exists", entry.Name)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err = CreateProvider(ctx, str, entry, promptForPAT)
+			cancel()
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("Failed to add SCM provider %s: %v\n", entry.Name, err)
+			continue
+		}
+		takenNames[entry.Name] = true
+		fmt.Printf("Added SCM provider %s\n", entry.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to add %d of %d SCM providers", failed, len(entries))
+	}
+	return nil
+}
+
+// CreateProvider validates the entry with its SCM client and creates the provider. When the entry has no PAT, it is
+// prompted for if promptForPAT is set and is an error otherwise.
+func CreateProvider(ctx context.Context, str store.SCMProvider, entry *types.SCMProviderEntry, promptForPAT bool) error {
+	host, err := NormaliseHost(entry.Host)
+	if err != nil {
+		return err
+	}
+
+	newProvider := &types.SCMProvider{
+		Type:        entry.Type,
+		Name:        entry.Name,
+		Host:        host,
+		API:         entry.API,
+		Concurrency: entry.Concurrency,
+		Updated:     time.Now().UnixMilli(),
+		Created:     time.Now().UnixMilli(),
+	}
+
+	err = ValidateProvider(newProvider)
+	if err != nil {
+		return err
+	}
+	if newProvider.Type != "github" {
+		newProvider.API = ""
+	}
+
+	var patRef string
+	patSources := 0
+	if entry.PAT != "" {
+		patSources++
+	}
+	if entry.PATEnv != "" {
+		patSources++
+		patRef = clientbuilder.PATRefEnvPrefix + entry.PATEnv
+	}
+	if entry.PATCmd != "" {
+		patSources++
+		patRef = clientbuilder.PATRefCmdPrefix + entry.PATCmd
+	}
+	if patSources > 1 || (patSources == 0 && !promptForPAT) {
+		return fmt.Errorf("exactly one of pat, pat_env and pat_cmd must be set")
+	}
+
+	var pat string
+	if patSources == 0 {
+		fmt.Printf("SCM provider %s has no PAT.\n", entry.Name)
+		pat, err = PromptForSecret()
+	} else {
+		pat, err = clientbuilder.ResolvePAT(&types.User{PAT: entry.PAT, PATRef: patRef})
+	}
+	if err != nil {
+		return err
+	}
+
+	err = FetchProviderDetails(ctx, newProvider, pat)
+	if err != nil {
+		return err
+	}
+
+	if patRef != "" {
+		newProvider.User.PAT = ""
+		newProvider.User.PATRef = patRef
+	}
+
+	return str.Create(*newProvider)
+}
+
+// ErrNoTerminal is returned by PromptForSecret when there is no terminal to prompt on.
+var ErrNoTerminal = errors.New("stdin is not a terminal to prompt for the PAT")
+
+// PromptForSecret reads the PAT from the terminal without echoing it.
+func PromptForSecret() (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", ErrNoTerminal
+	}
+	fmt.Println("Enter the PAT (Personal Access Token):")
+	patBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("error reading PAT: %w", err)
+	}
+	return string(patBytes), nil
+}
+
+// ReadSecret reads the PAT from the first line of the reader, eg stdin piped from a secret manager.
+func ReadSecret(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading PAT: %w", err)
+	}
+	pat := strings.TrimSpace(line)
+	if pat == "" {
+		return "", fmt.Errorf("no PAT was provided")
+	}
+	return pat, nil
+}
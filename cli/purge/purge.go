@@ -23,7 +23,7 @@ func (c *command) run(*kingpin.ParseContext) error {
 		}
 		fmt.Println("Proceeding with the purge...")
 	}
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 	err := str.Purge()
 	if err != nil {
 		return err
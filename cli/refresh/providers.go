@@ -7,6 +7,7 @@ import (
 	"github.com/dhruv1397/prm/cli"
 	"github.com/dhruv1397/prm/clientbuilder"
 	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/transport"
 	"github.com/dhruv1397/prm/types"
 	"github.com/dhruv1397/prm/util"
 	"sync"
@@ -22,9 +23,9 @@ func (c *providersCommand) run(*kingpin.ParseContext) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 
-	providers, err := str.List(c.name, c.providerType)
+	providers, err := str.List(c.providerType, c.name)
 	if err != nil {
 		return err
 	}
@@ -39,55 +40,40 @@ func (c *providersCommand) run(*kingpin.ParseContext) error {
 	var providersMu sync.Mutex
 	var errorsMu sync.Mutex
 
-	for _, provider := range providers {
-		wg.Add(1)
-		go func(provider *types.SCMProvider) {
-			defer wg.Done()
+	// NOTE: The SCM providers are started in the background, no more than the global concurrency at once, so that their
+	// results are collected meanwhile.
+	limiter := transport.NewLimiter(clientbuilder.GlobalConcurrency())
+	go func() {
+		for _, provider := range providers {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+			go func(provider *types.SCMProvider) {
+				defer wg.Done()
+				defer limiter.Release()
 
-			var currentProvider = *provider
-			if currentProvider.Type == "github" {
-				scmClient, err := clientbuilder.GetGithubSCMClient(ctx, currentProvider.User.PAT)
+				var currentProvider = *provider
+				pat, err := clientbuilder.ResolvePAT(currentProvider.User)
 				if err != nil {
-					errCh <- err
+					errCh <- fmt.Errorf("error resolving PAT of SCM provider %s: %w", currentProvider.Name, err)
 					return
 				}
-				gituhbUser, err := scmClient.GetUser(ctx)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				gituhbUser.PAT = currentProvider.User.PAT
-				currentProvider.User = gituhbUser
 
-			} else if currentProvider.Type == "harness" {
-				scmClient, err := clientbuilder.GetHarnessSCMClient(currentProvider.Host, currentProvider.User.PAT)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				harnessUser, err := scmClient.GetUser(ctx)
+				err = cli.FetchProviderDetails(ctx, &currentProvider, pat)
 				if err != nil {
 					errCh <- err
 					return
 				}
-				currentProvider.User = harnessUser
-				repos, err := scmClient.GetRepos(ctx)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				currentProvider.Repos = repos
-
-			} else {
-				errCh <- fmt.Errorf("unknown provider type: %s", currentProvider.Type)
-				return
-			}
 
-			respCh <- currentProvider
-		}(provider)
-	}
+				// NOTE: The fetched user only carries the resolved PAT, keep where it is kept.
+				currentProvider.User.PAT = provider.User.PAT
+				currentProvider.User.PATRef = provider.User.PATRef
 
-	go func() {
+				respCh <- currentProvider
+			}(provider)
+		}
 		wg.Wait()
 		close(respCh)
 		close(errCh)
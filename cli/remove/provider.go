@@ -11,7 +11,7 @@ type providerCommand struct {
 }
 
 func (c *providerCommand) run(*kingpin.ParseContext) error {
-	str := store.NewSCMProviderImpl()
+	str := store.NewSCMProvider()
 	err := str.Delete(c.name)
 	if err != nil {
 		return err
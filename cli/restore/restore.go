@@ -0,0 +1,150 @@
+package restore
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/dhruv1397/prm/cli"
+	"github.com/dhruv1397/prm/cli/export"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/store"
+	"github.com/dhruv1397/prm/types"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+type command struct {
+	file string
+}
+
+func (c *command) run(*kingpin.ParseContext) error {
+	content, err := cli.ReadInput(c.file)
+	if err != nil {
+		return err
+	}
+
+	if encryption.IsEncrypted(content) {
+		passphrase, err := encryption.ReadPassphrase(export.PassphraseEnv, "Enter the passphrase of the export:",
+			false)
+		if err != nil {
+			return err
+		}
+		content, err = encryption.Decrypt(content, passphrase)
+		if err != nil {
+			return fmt.Errorf("error decrypting %s: %w", c.file, err)
+		}
+	}
+
+	exported := &types.SCMProviderExport{}
+	err = yaml.Unmarshal(content, exported)
+	if err != nil {
+		return fmt.Errorf("error deserialising %s: %w", c.file, err)
+	}
+	if exported.Version == 0 {
+		return fmt.Errorf("%s is not a prm export, use import providers to add providers from a file", c.file)
+	}
+	if exported.Version > export.Version {
+		return fmt.Errorf("%s was exported by a newer version of prm, export version %d is not supported",
+			c.file, exported.Version)
+	}
+
+	entries := make([]*types.SCMProviderEntry, 0, len(exported.Providers))
+	for _, entry := range exported.Providers {
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 && len(exported.Profiles) == 0 {
+		fmt.Println("No providers to restore!")
+		return nil
+	}
+
+	// NOTE: Redacted PATs are prompted for, which is the point of redacting them when moving to another machine. The
+	// prompt reads stdin, so it cannot be used when the export itself is read from there.
+	if cli.IsStdin(c.file) {
+		redacted := getRedactedProviders(entries)
+		if len(redacted) > 0 {
+			return fmt.Errorf("the PATs of SCM providers %s are redacted and cannot be prompted for while the export "+
+				"is read from stdin, pass the path of the export to --%s instead", strings.Join(redacted, ", "), cli.FlagFile)
+		}
+	}
+
+	str := store.NewSCMProvider()
+	providersErr := cli.CreateProviders(str, entries, true)
+	// NOTE: The profiles are restored even when some providers failed, without those providers.
+	profilesErr := restoreProfiles(str, exported)
+	if providersErr != nil {
+		return providersErr
+	}
+	return profilesErr
+}
+
+func getRedactedProviders(entries []*types.SCMProviderEntry) []string {
+	redacted := make([]string, 0)
+	for _, entry := range entries {
+		if entry.PAT == "" && entry.PATEnv == "" && entry.PATCmd == "" {
+			redacted = append(redacted, entry.Name)
+		}
+	}
+	return redacted
+}
+
+// restoreProfiles creates the exported profiles with those of their providers which exist, and uses the profile which
+// was in use when it is restored.
+func restoreProfiles(str store.SCMProvider, exported *types.SCMProviderExport) error {
+	providers, _, _, err := str.ListAll()
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, provider := range providers {
+		existing[provider.Name] = true
+	}
+
+	restored := map[string]bool{}
+	failed := 0
+	total := 0
+	for _, profile := range exported.Profiles {
+		if profile == nil {
+			continue
+		}
+		total++
+		kept := make([]string, 0, len(profile.Providers))
+		for _, name := range profile.Providers {
+			if existing[name] {
+				kept = append(kept, name)
+			} else {
+				fmt.Printf("Left SCM provider %s out of profile %s as it was not restored\n", name, profile.Name)
+			}
+		}
+
+		err = str.CreateProfile(types.Profile{Name: profile.Name, Providers: kept})
+		if err != nil {
+			failed++
+			fmt.Printf("Failed to add profile %s: %v\n", profile.Name, err)
+			continue
+		}
+		restored[profile.Name] = true
+		fmt.Printf("Added profile %s\n", profile.Name)
+	}
+
+	if restored[exported.CurrentProfile] {
+		err = str.UseProfile(exported.CurrentProfile)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using profile %s\n", exported.CurrentProfile)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to add %d of %d profiles", failed, total)
+	}
+	return nil
+}
+
+func Register(app *kingpin.Application) {
+	c := &command{}
+
+	cmd := app.Command(cli.CommandRestore, cli.CommandRestoreHelpText).Action(c.run)
+
+	cmd.Flag(cli.FlagFile, cli.FlagFileHelpText).Short(cli.FlagFileShort).Required().StringVar(&c.file)
+}
@@ -0,0 +1,20 @@
+package clientbuilder
+
+import (
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/scmclient"
+	"github.com/dhruv1397/prm/types"
+)
+
+func GetAzureSCMClient(provider *types.SCMProvider, pat string) (*scmclient.AzureSCMClient, error) {
+	return scmclient.NewAzureSCMClient(getHTTPClient(provider), provider.Host, pat, getConcurrency(provider))
+}
+
+func GetAzurePRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewAzurePRClient(getHTTPClient(provider), provider.Host, user, provider.Repos,
+		provider.Name, getConcurrency(provider))
+}
@@ -0,0 +1,33 @@
+package clientbuilder
+
+import (
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/scmclient"
+	"github.com/dhruv1397/prm/types"
+)
+
+func GetBitbucketCloudSCMClient(provider *types.SCMProvider, pat string) (*scmclient.BitbucketCloudSCMClient, error) {
+	return scmclient.NewBitbucketCloudSCMClient(getHTTPClient(provider), provider.Host, pat)
+}
+
+func GetBitbucketCloudPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewBitbucketCloudPRClient(getHTTPClient(provider), provider.Host, user, provider.Name,
+		getConcurrency(provider))
+}
+
+func GetBitbucketDCSCMClient(provider *types.SCMProvider, pat string) (*scmclient.BitbucketDCSCMClient, error) {
+	return scmclient.NewBitbucketDCSCMClient(getHTTPClient(provider), provider.Host, pat)
+}
+
+func GetBitbucketDCPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewBitbucketDCPRClient(getHTTPClient(provider), provider.Host, user, provider.Name,
+		getConcurrency(provider))
+}
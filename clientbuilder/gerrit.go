@@ -0,0 +1,20 @@
+package clientbuilder
+
+import (
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/scmclient"
+	"github.com/dhruv1397/prm/types"
+)
+
+func GetGerritSCMClient(provider *types.SCMProvider, pat string) (*scmclient.GerritSCMClient, error) {
+	return scmclient.NewGerritSCMClient(getHTTPClient(provider), provider.Host, pat)
+}
+
+func GetGerritPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewGerritPRClient(getHTTPClient(provider), provider.Host, user, provider.Name,
+		getConcurrency(provider))
+}
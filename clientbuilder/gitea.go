@@ -0,0 +1,20 @@
+package clientbuilder
+
+import (
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/scmclient"
+	"github.com/dhruv1397/prm/types"
+)
+
+func GetGiteaSCMClient(provider *types.SCMProvider, pat string) (*scmclient.GiteaSCMClient, error) {
+	return scmclient.NewGiteaSCMClient(getHTTPClient(provider), provider.Host, pat)
+}
+
+func GetGiteaPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewGiteaPRClient(getHTTPClient(provider), provider.Host, user, provider.Name,
+		getConcurrency(provider))
+}
@@ -2,26 +2,86 @@ package clientbuilder
 
 import (
 	"context"
+	"fmt"
 	"github.com/dhruv1397/prm/prclient"
 	"github.com/dhruv1397/prm/scmclient"
 	"github.com/dhruv1397/prm/types"
 	"github.com/google/go-github/v64/github"
 	"golang.org/x/oauth2"
+	"net/http"
+	"net/url"
 )
 
-func GetGithubSCMClient(ctx context.Context, pat string) (*scmclient.GithubSCMClient, error) {
-	return scmclient.NewGithubSCMClient(getGithubClientWithPAT(ctx, pat))
+const (
+	githubHost = "github.com"
+
+	githubGraphQLURL           = "https://api.github.com/graphql"
+	githubEnterpriseGraphQLURL = "%s/api/graphql"
+)
+
+func GetGithubSCMClient(ctx context.Context, provider *types.SCMProvider, pat string) (*scmclient.GithubSCMClient, error) {
+	client, err := getGithubClientWithPAT(ctx, provider, pat)
+	if err != nil {
+		return nil, err
+	}
+	return scmclient.NewGithubSCMClient(client)
+}
+
+func GetGithubPRClient(ctx context.Context, provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	client, err := getGithubClientWithPAT(ctx, provider, user.PAT)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewGithubPRClient(user, client, provider.Name, getConcurrency(provider))
 }
 
-func GetGithubPRClient(ctx context.Context, user *types.User, providerName string) (prclient.PRClient, error) {
-	return prclient.NewGithubPRClient(user, getGithubClientWithPAT(ctx, user.PAT), providerName)
+func GetGithubGraphQLPRClient(ctx context.Context, provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	graphQLURL := githubGraphQLURL
+	if isGithubEnterprise(provider.Host) {
+		graphQLURL = fmt.Sprintf(githubEnterpriseGraphQLURL, provider.Host)
+	}
+	return prclient.NewGithubGraphQLPRClient(user, getGithubHTTPClientWithPAT(ctx, provider, user.PAT),
+		graphQLURL, provider.Name)
 }
 
-func getGithubClientWithPAT(ctx context.Context, pat string) *github.Client {
+func getGithubHTTPClientWithPAT(ctx context.Context, provider *types.SCMProvider, pat string) *http.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: pat},
 	)
-	tc := oauth2.NewClient(ctx, ts)
-	newClient := github.NewClient(tc)
-	return newClient
+	return oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, getHTTPClient(provider)), ts)
+}
+
+func getGithubClientWithPAT(ctx context.Context, provider *types.SCMProvider, pat string) (*github.Client, error) {
+	host := provider.Host
+	newClient := github.NewClient(getGithubHTTPClientWithPAT(ctx, provider, pat))
+	if isGithubEnterprise(host) {
+		// NOTE: GitHub Enterprise Server serves the REST API under /api/v3 and uploads under /api/uploads, which
+		// WithEnterpriseURLs appends to the host.
+		enterpriseClient, err := newClient.WithEnterpriseURLs(host, host)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring github enterprise host %s: %w", host, err)
+		}
+		return enterpriseClient, nil
+	}
+	return newClient, nil
+}
+
+func isGithubEnterprise(host string) bool {
+	if host == "" {
+		return false
+	}
+	parsedHost, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+	return parsedHost.Hostname() != githubHost && parsedHost.Hostname() != "api."+githubHost &&
+		parsedHost.Hostname() != "www."+githubHost
 }
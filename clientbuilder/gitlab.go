@@ -0,0 +1,20 @@
+package clientbuilder
+
+import (
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/scmclient"
+	"github.com/dhruv1397/prm/types"
+)
+
+func GetGitlabSCMClient(provider *types.SCMProvider, pat string) (*scmclient.GitlabSCMClient, error) {
+	return scmclient.NewGitlabSCMClient(getHTTPClient(provider), provider.Host, pat)
+}
+
+func GetGitlabPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewGitlabPRClient(getHTTPClient(provider), provider.Host, user, provider.Name,
+		getConcurrency(provider))
+}
@@ -6,10 +6,15 @@ import (
 	"github.com/dhruv1397/prm/types"
 )
 
-func GetHarnessSCMClient(host string, pat string) (*scmclient.HarnessSCMClient, error) {
-	return scmclient.NewHarnessSCMClient(host, pat)
+func GetHarnessSCMClient(provider *types.SCMProvider, pat string) (*scmclient.HarnessSCMClient, error) {
+	return scmclient.NewHarnessSCMClient(getHTTPClient(provider), provider.Host, pat, getConcurrency(provider))
 }
 
-func GetHarnessPRClient(host string, user *types.User, repos []*types.Repo, providerName string) (prclient.PRClient, error) {
-	return prclient.NewHarnessPRClient(host, user, repos, providerName)
+func GetHarnessPRClient(provider *types.SCMProvider) (prclient.PRClient, error) {
+	user, err := resolveUser(provider)
+	if err != nil {
+		return nil, err
+	}
+	return prclient.NewHarnessPRClient(getHTTPClient(provider), provider.Host, user, provider.Repos,
+		provider.Name, getConcurrency(provider))
 }
@@ -0,0 +1,66 @@
+package clientbuilder
+
+import (
+	"fmt"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+	"sync"
+)
+
+// DefaultConcurrency is the number of requests that may be in flight at once across all the SCM providers.
+const DefaultConcurrency = 16
+
+// Stats collects the requests sent by every client built by this package, for all the SCM providers.
+var Stats = transport.NewStats()
+
+var globalConcurrency = DefaultConcurrency
+
+var globalLimiter = transport.NewLimiter(DefaultConcurrency)
+
+// providerLimiters holds the limiter of every SCM provider by name, shared by all the clients built for the provider so
+// that its concurrency bounds all of its requests.
+var providerLimiters sync.Map
+
+// SetConcurrency changes the number of requests that may be in flight at once across all the SCM providers. It has
+// to be called before any client is built.
+func SetConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+	}
+	globalConcurrency = concurrency
+	globalLimiter = transport.NewLimiter(concurrency)
+	return nil
+}
+
+// getHTTPClient returns the client used to talk to an SCM provider. Requests are retried on rate limits and server
+// errors, and are bounded both by the concurrency of the provider, if set, and by the global concurrency.
+func getHTTPClient(provider *types.SCMProvider) *http.Client {
+	limitTransport := transport.NewLimitTransport(http.DefaultTransport, getProviderLimiter(provider), globalLimiter)
+	return &http.Client{
+		Transport: transport.NewRetryTransport(limitTransport, Stats),
+	}
+}
+
+func getProviderLimiter(provider *types.SCMProvider) *transport.Limiter {
+	if limiter, ok := providerLimiters.Load(provider.Name); ok {
+		return limiter.(*transport.Limiter)
+	}
+	limiter, _ := providerLimiters.LoadOrStore(provider.Name, transport.NewLimiter(provider.Concurrency))
+	return limiter.(*transport.Limiter)
+}
+
+// GlobalConcurrency returns the number of requests that may be in flight at once across all the SCM providers, which
+// also bounds how many SCM providers are worked on at once.
+func GlobalConcurrency() int {
+	return globalConcurrency
+}
+
+// getConcurrency returns the number of requests that may be in flight at once to the provider. The clients start no
+// more PRs, repos or projects at once than that, so that they do not park a goroutine on the limiters for each of them.
+func getConcurrency(provider *types.SCMProvider) int {
+	if provider.Concurrency > 0 && provider.Concurrency < globalConcurrency {
+		return provider.Concurrency
+	}
+	return globalConcurrency
+}
@@ -0,0 +1,82 @@
+package clientbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/dhruv1397/prm/types"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// PATRefEnvPrefix marks a PAT read from an environment variable, eg env:GITHUB_TOKEN.
+	PATRefEnvPrefix = "env:"
+	// PATRefCmdPrefix marks a PAT printed by a credential helper command, eg cmd:gh auth token.
+	PATRefCmdPrefix = "cmd:"
+)
+
+// IsPATSource reports whether the PAT reference is resolved at runtime rather than stored by prm.
+func IsPATSource(patRef string) bool {
+	return strings.HasPrefix(patRef, PATRefEnvPrefix) || strings.HasPrefix(patRef, PATRefCmdPrefix)
+}
+
+// ResolvePAT returns the PAT of the user, reading it from its environment variable or credential helper command
+// when it references one.
+func ResolvePAT(user *types.User) (string, error) {
+	if user == nil {
+		return "", fmt.Errorf("SCM provider has no user")
+	}
+	switch {
+	case strings.HasPrefix(user.PATRef, PATRefEnvPrefix):
+		name := strings.TrimPrefix(user.PATRef, PATRefEnvPrefix)
+		pat := strings.TrimSpace(os.Getenv(name))
+		if pat == "" {
+			return "", fmt.Errorf("environment variable %s holding the PAT is not set", name)
+		}
+		return pat, nil
+	case strings.HasPrefix(user.PATRef, PATRefCmdPrefix):
+		return runPATCommand(strings.TrimPrefix(user.PATRef, PATRefCmdPrefix))
+	default:
+		return user.PAT, nil
+	}
+}
+
+func runPATCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// NOTE: Credential helpers like pass may need to prompt, eg for a GPG passphrase.
+	cmd.Stdin = os.Stdin
+
+	err := cmd.Run()
+	if err != nil {
+		if stderrOutput := strings.TrimSpace(stderr.String()); stderrOutput != "" {
+			return "", fmt.Errorf("error running PAT command %q: %w: %s", command, err, stderrOutput)
+		}
+		return "", fmt.Errorf("error running PAT command %q: %w", command, err)
+	}
+	pat := strings.TrimSpace(stdout.String())
+	if pat == "" {
+		return "", fmt.Errorf("PAT command %q printed no PAT", command)
+	}
+	return pat, nil
+}
+
+// resolveUser returns a copy of the user of the provider with its PAT resolved.
+func resolveUser(provider *types.SCMProvider) (*types.User, error) {
+	pat, err := ResolvePAT(provider.User)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving PAT of SCM provider %s: %w", provider.Name, err)
+	}
+	user := *provider.User
+	user.PAT = pat
+	return &user, nil
+}
@@ -4,11 +4,21 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/dhruv1397/prm/cli"
 	"github.com/dhruv1397/prm/cli/add"
+	"github.com/dhruv1397/prm/cli/config"
+	"github.com/dhruv1397/prm/cli/export"
+	"github.com/dhruv1397/prm/cli/imports"
 	"github.com/dhruv1397/prm/cli/list"
+	"github.com/dhruv1397/prm/cli/profile"
 	"github.com/dhruv1397/prm/cli/purge"
 	"github.com/dhruv1397/prm/cli/refresh"
 	"github.com/dhruv1397/prm/cli/remove"
+	"github.com/dhruv1397/prm/cli/restore"
+	"github.com/dhruv1397/prm/clientbuilder"
+	"github.com/dhruv1397/prm/prclient"
+	"github.com/dhruv1397/prm/store"
 	"github.com/dhruv1397/prm/version"
+	"os"
+	"strconv"
 )
 
 const (
@@ -19,12 +29,38 @@ const (
 func main() {
 	args := cli.GetArguments()
 
+	var verbose bool
+	var configFilePath string
+	var profileName string
+	// NOTE: Flag defaults are not applied when --help or --version is passed, but pre-actions still run.
+	var concurrency = clientbuilder.DefaultConcurrency
+
 	app := kingpin.New(application, description)
+	app.Flag(cli.FlagVerbose, cli.FlagVerboseHelpText).BoolVar(&verbose)
+	app.Flag(cli.FlagConcurrency, cli.FlagConcurrencyHelpText).
+		Default(strconv.Itoa(clientbuilder.DefaultConcurrency)).IntVar(&concurrency)
+	app.Flag(cli.FlagConfig, cli.FlagConfigHelpText).Envar(cli.EnvConfig).StringVar(&configFilePath)
+	app.Flag(cli.FlagProfile, cli.FlagProfileHelpText).Envar(cli.EnvProfile).StringVar(&profileName)
+	app.PreAction(func(*kingpin.ParseContext) error {
+		store.SetConfigFilePath(configFilePath)
+		store.SetProfile(profileName)
+		prclient.SetVerbose(verbose)
+		return clientbuilder.SetConcurrency(concurrency)
+	})
 	list.Register(app)
 	add.Register(app)
 	remove.Register(app)
 	refresh.Register(app)
 	purge.Register(app)
+	config.Register(app)
+	imports.Register(app)
+	export.Register(app)
+	restore.Register(app)
+	profile.Register(app)
 	app.Version(version.Version.String())
-	kingpin.MustParse(app.Parse(args))
+	command, err := app.Parse(args)
+	if verbose {
+		clientbuilder.Stats.PrintSummary(os.Stderr)
+	}
+	kingpin.MustParse(command, err)
 }
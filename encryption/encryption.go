@@ -0,0 +1,111 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	envelopeVersion = 1
+	kdfScrypt       = "scrypt"
+
+	// NOTE: See https://pkg.go.dev/golang.org/x/crypto/scrypt#Key for the recommended parameters.
+	scryptN    = 1 << 15
+	scryptR    = 8
+	scryptP    = 1
+	keyLength  = 32
+	saltLength = 16
+)
+
+// ErrWrongPassphrase is returned by Decrypt when the data cannot be authenticated with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted data")
+
+// envelope is the serialised form of encrypted data. It carries everything but the passphrase needed to decrypt it.
+type envelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Encrypt seals the plaintext with AES-256-GCM under a key derived from the passphrase with scrypt.
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	data, err := json.Marshal(&envelope{
+		Version:    envelopeVersion,
+		KDF:        kdfScrypt,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error serialising encrypted data: %w", err)
+	}
+	return data, nil
+}
+
+// Decrypt opens data sealed by Encrypt.
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	sealed := &envelope{}
+	if err := json.Unmarshal(data, sealed); err != nil {
+		return nil, fmt.Errorf("error deserialising encrypted data: %w", err)
+	}
+	if sealed.Version != envelopeVersion || sealed.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported encrypted data version %d with key derivation %s", sealed.Version,
+			sealed.KDF)
+	}
+	gcm, err := newGCM(passphrase, sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether data looks like the output of Encrypt.
+func IsEncrypted(data []byte) bool {
+	sealed := &envelope{}
+	return json.Unmarshal(data, sealed) == nil && sealed.KDF != "" && len(sealed.Ciphertext) > 0
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key from passphrase: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{"config", []byte("version: 1\nproviders:\n- type: github\n  name: gh\n")},
+		{"empty", []byte{}},
+		{"binary", []byte{0, 1, 2, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encrypt(tt.plaintext, "secret")
+			if err != nil {
+				t.Fatalf("Encrypt returned error: %v", err)
+			}
+			if len(tt.plaintext) > 0 && bytes.Contains(data, tt.plaintext) {
+				t.Errorf("encrypted data %q contains the plaintext", data)
+			}
+			if !IsEncrypted(data) {
+				t.Errorf("IsEncrypted(%q) is false, want true", data)
+			}
+
+			plaintext, err := Decrypt(data, "secret")
+			if err != nil {
+				t.Fatalf("Decrypt returned error: %v", err)
+			}
+			if !bytes.Equal(plaintext, tt.plaintext) {
+				t.Errorf("Decrypt returned %q, want %q", plaintext, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestEncryptUsesFreshSaltAndNonce(t *testing.T) {
+	first, err := Encrypt([]byte("pat"), "secret")
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+	second, err := Encrypt([]byte("pat"), "secret")
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Errorf("encrypting the same plaintext twice returned the same data %q", first)
+	}
+}
+
+func TestEncryptRejectsEmptyPassphrase(t *testing.T) {
+	if _, err := Encrypt([]byte("pat"), ""); err == nil {
+		t.Error("Encrypt with an empty passphrase returned no error")
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	data, err := Encrypt([]byte("pat"), "secret")
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+	tampered := &envelope{}
+	if err := json.Unmarshal(data, tampered); err != nil {
+		t.Fatalf("error deserialising encrypted data: %v", err)
+	}
+	tampered.Ciphertext[0] ^= 1
+	tamperedData, err := json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("error serialising encrypted data: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		data                []byte
+		passphrase          string
+		wantWrongPassphrase bool
+	}{
+		{"wrong passphrase", data, "guess", true},
+		{"tampered ciphertext", tamperedData, "secret", true},
+		{"not encrypted", []byte("version: 1\n"), "secret", false},
+		{"unsupported version", []byte(`{"version":2,"kdf":"scrypt","ciphertext":"AA=="}`), "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.data, tt.passphrase)
+			if err == nil {
+				t.Fatal("Decrypt returned no error")
+			}
+			if got := errors.Is(err, ErrWrongPassphrase); got != tt.wantWrongPassphrase {
+				t.Errorf("Decrypt returned error %v, want ErrWrongPassphrase %v", err, tt.wantWrongPassphrase)
+			}
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"yaml config", "version: 1\nproviders: []\n", false},
+		{"empty", "", false},
+		{"json without ciphertext", `{"kdf":"scrypt"}`, false},
+		{"json without kdf", `{"ciphertext":"AA=="}`, false},
+		{"envelope", `{"version":1,"kdf":"scrypt","ciphertext":"AA=="}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEncrypted([]byte(tt.data)); got != tt.want {
+				t.Errorf("IsEncrypted(%q) is %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
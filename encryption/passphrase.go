@@ -0,0 +1,46 @@
+package encryption
+
+import (
+	"fmt"
+	"golang.org/x/term"
+	"os"
+)
+
+// ReadPassphrase returns the passphrase set in the environment variable, or prompts for it when stdin is a
+// terminal. With confirm set the passphrase has to be typed twice, which is meant for setting a new one.
+func ReadPassphrase(envName string, prompt string, confirm bool) (string, error) {
+	if passphrase := os.Getenv(envName); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%s is not set and stdin is not a terminal to prompt for the passphrase", envName)
+	}
+
+	passphrase, err := promptForPassphrase(prompt)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	if confirm {
+		confirmation, err := promptForPassphrase("Confirm the passphrase:")
+		if err != nil {
+			return "", err
+		}
+		if confirmation != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func promptForPassphrase(prompt string) (string, error) {
+	// NOTE: Prompts go to stderr so that they do not end up in json or yaml output piped elsewhere.
+	fmt.Fprintln(os.Stderr, prompt)
+	passphraseBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %w", err)
+	}
+	return string(passphraseBytes), nil
+}
@@ -0,0 +1,156 @@
+package filter
+
+import (
+	"github.com/dhruv1397/prm/types"
+	"slices"
+	"strings"
+	"time"
+)
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindList
+	kindDuration
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "boolean"
+	case kindList:
+		return "list"
+	default:
+		return "duration"
+	}
+}
+
+// durationUnits are the units of duration literals, eg 30m, 12h, 7d or 2w.
+const durationUnits = "mhdw"
+
+var durationUnitLengths = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// field of a PR which expressions can refer to. value returns a string, int, bool, []string or time.Duration depending
+// on the kind, and false when the provider does not report the field.
+type field struct {
+	kind  valueKind
+	value func(pr *types.PullRequest, now time.Time) (any, bool)
+}
+
+var fields = map[string]field{
+	"number":            numberField(func(pr *types.PullRequest) int { return pr.Number }),
+	"title":             stringField(func(pr *types.PullRequest) string { return pr.Title }),
+	"state":             stringField(func(pr *types.PullRequest) string { return pr.State }),
+	"url":               stringField(func(pr *types.PullRequest) string { return pr.URL }),
+	"type":              stringField(func(pr *types.PullRequest) string { return pr.SCMProviderType }),
+	"name":              stringField(func(pr *types.PullRequest) string { return pr.SCMProviderName }),
+	"repo":              detailField(stringField(func(pr *types.PullRequest) string { return pr.Repo })),
+	"author":            detailField(stringField(func(pr *types.PullRequest) string { return pr.Author })),
+	"source_branch":     detailField(stringField(func(pr *types.PullRequest) string { return pr.SourceBranch })),
+	"target_branch":     detailField(stringField(func(pr *types.PullRequest) string { return pr.TargetBranch })),
+	"my_review":         stringField(func(pr *types.PullRequest) string { return pr.MyReview }),
+	"mergeable":         mergeableField(),
+	"draft":             detailField(boolField(func(pr *types.PullRequest) bool { return pr.Draft })),
+	"approved":          listField(func(pr *types.PullRequest) []string { return pr.Approved }),
+	"commented":         listField(func(pr *types.PullRequest) []string { return pr.Commented }),
+	"requested_changes": listField(func(pr *types.PullRequest) []string { return pr.RequestedChanges }),
+	"pending_reviewers": listField(func(pr *types.PullRequest) []string { return pr.PendingReviewers }),
+	"labels":            detailField(listField(func(pr *types.PullRequest) []string { return pr.Labels })),
+	"checks.passing":    checksField(func(checks *types.ChecksSummary) int { return checks.Passing }),
+	"checks.failing":    checksField(func(checks *types.ChecksSummary) int { return checks.Failing }),
+	"checks.pending":    checksField(func(checks *types.ChecksSummary) int { return checks.Pending }),
+	"age":               sinceField(func(pr *types.PullRequest) int64 { return pr.Created }),
+	"idle":              sinceField(func(pr *types.PullRequest) int64 { return pr.Updated }),
+}
+
+func fieldNames() string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+func stringField(fn func(pr *types.PullRequest) string) field {
+	return field{kind: kindString, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		return fn(pr), true
+	}}
+}
+
+func numberField(fn func(pr *types.PullRequest) int) field {
+	return field{kind: kindNumber, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		return fn(pr), true
+	}}
+}
+
+func boolField(fn func(pr *types.PullRequest) bool) field {
+	return field{kind: kindBool, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		return fn(pr), true
+	}}
+}
+
+func listField(fn func(pr *types.PullRequest) []string) field {
+	return field{kind: kindList, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		return fn(pr), true
+	}}
+}
+
+// mergeableField is unknown when the provider cannot tell whether the PR can be merged, eg for merged PRs.
+func mergeableField() field {
+	return field{kind: kindBool, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		if pr.Mergeable != "true" && pr.Mergeable != "false" {
+			return false, false
+		}
+		return pr.Mergeable == "true", true
+	}}
+}
+
+// detailedProviderTypes are the providers which report the repo, branches, author, draft flag and labels of their PRs.
+var detailedProviderTypes = []string{"github", "harness"}
+
+// detailField is unknown for the PRs of the providers which do not report it, so that eg !draft or author != "bob" do
+// not hold for every PR of such providers.
+func detailField(f field) field {
+	value := f.value
+	f.value = func(pr *types.PullRequest, now time.Time) (any, bool) {
+		if !slices.Contains(detailedProviderTypes, pr.SCMProviderType) {
+			return nil, false
+		}
+		return value(pr, now)
+	}
+	return f
+}
+
+// checksField is unknown for the PRs whose checks are not fetched, see types.PullRequest.Checks.
+func checksField(fn func(checks *types.ChecksSummary) int) field {
+	return field{kind: kindNumber, value: func(pr *types.PullRequest, _ time.Time) (any, bool) {
+		if pr.Checks == nil {
+			return 0, false
+		}
+		return fn(pr.Checks), true
+	}}
+}
+
+// sinceField is the time elapsed since the timestamp in milliseconds, unknown when the provider does not report it.
+func sinceField(fn func(pr *types.PullRequest) int64) field {
+	return field{kind: kindDuration, value: func(pr *types.PullRequest, now time.Time) (any, bool) {
+		timestamp := fn(pr)
+		if timestamp == 0 {
+			return time.Duration(0), false
+		}
+		return now.Sub(time.UnixMilli(timestamp)), true
+	}}
+}
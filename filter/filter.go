@@ -0,0 +1,375 @@
+// Package filter implements the expressions selecting which of the fetched PRs are listed, eg
+//
+//	mergeable && approved >= 2 && !draft
+//	repo ~ "api-.*" && (age > 7d || checks.failing > 0)
+//
+// An expression combines fields of the PR with &&, || and !, and compares them with ==, !=, >, >=, <, <=, ~ (matches
+// the regular expression anywhere in the value) and !~ (does not match it). Lists compare by their length against
+// numbers and by their elements against strings, eg labels == "bug" holds when one of the labels is bug. A field on its
+// own is true when it is set, ie a true boolean, a non-zero number or a non-empty string or list.
+//
+// Fields which the provider of the PR does not report, such as the author of a GitLab MR or the age of a PR without a
+// creation time, are unknown. Comparisons on them and their negations are unknown too, && is false when either side is
+// false and || is true when either side is true, and PRs for which the whole expression is unknown are not matched.
+package filter
+
+import (
+	"cmp"
+	"github.com/dhruv1397/prm/types"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Filter selects the PRs matching an expression.
+type Filter struct {
+	root node
+}
+
+// Parse parses the expression, see the package documentation for its syntax.
+func Parse(expression string) (*Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, errorAt(next.pos, "expected && or || before %s", describe(next))
+	}
+	return &Filter{root: root}, nil
+}
+
+// Match reports whether the PR matches the expression.
+func (f *Filter) Match(pr *types.PullRequest) bool {
+	matched, known := f.root.eval(pr, time.Now())
+	return known && matched
+}
+
+// node evaluates to whether the PR matches it, and to false as the second result when that is unknown as it depends on
+// fields which the provider does not report.
+type node interface {
+	eval(pr *types.PullRequest, now time.Time) (bool, bool)
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) eval(pr *types.PullRequest, now time.Time) (bool, bool) {
+	left, leftKnown := n.left.eval(pr, now)
+	if leftKnown && !left {
+		return false, true
+	}
+	right, rightKnown := n.right.eval(pr, now)
+	if rightKnown && !right {
+		return false, true
+	}
+	return true, leftKnown && rightKnown
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) eval(pr *types.PullRequest, now time.Time) (bool, bool) {
+	left, leftKnown := n.left.eval(pr, now)
+	if leftKnown && left {
+		return true, true
+	}
+	right, rightKnown := n.right.eval(pr, now)
+	if rightKnown && right {
+		return true, true
+	}
+	return false, leftKnown && rightKnown
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(pr *types.PullRequest, now time.Time) (bool, bool) {
+	operand, known := n.operand.eval(pr, now)
+	return !operand, known
+}
+
+// fieldNode is a field on its own, true when it is set.
+type fieldNode struct {
+	field field
+}
+
+func (n *fieldNode) eval(pr *types.PullRequest, now time.Time) (bool, bool) {
+	value, ok := n.field.value(pr, now)
+	if !ok {
+		return false, false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case int:
+		return v != 0, true
+	case string:
+		return v != "", true
+	case []string:
+		return len(v) > 0, true
+	case time.Duration:
+		return v > 0, true
+	}
+	return false, false
+}
+
+type literal struct {
+	kind  valueKind
+	value any
+}
+
+type comparisonNode struct {
+	field   field
+	op      tokenKind
+	literal literal
+	// regex is compiled once while parsing for ~ and !~.
+	regex *regexp.Regexp
+}
+
+func (n *comparisonNode) eval(pr *types.PullRequest, now time.Time) (bool, bool) {
+	value, ok := n.field.value(pr, now)
+	if !ok {
+		return false, false
+	}
+	return n.compare(value), true
+}
+
+func (n *comparisonNode) compare(value any) bool {
+	negated := n.op == tokenNe || n.op == tokenNotMatch
+	switch v := value.(type) {
+	case string:
+		return n.matches(v) != negated
+	case int:
+		return compare(v, n.literal.value.(int), n.op)
+	case bool:
+		return (v == n.literal.value.(bool)) != negated
+	case time.Duration:
+		return compare(v, n.literal.value.(time.Duration), n.op)
+	case []string:
+		if n.literal.kind == kindNumber {
+			return compare(len(v), n.literal.value.(int), n.op)
+		}
+		return slices.ContainsFunc(v, n.matches) != negated
+	}
+	return false
+}
+
+// matches reports whether the string equals or, for ~ and !~, matches the literal.
+func (n *comparisonNode) matches(value string) bool {
+	if n.regex != nil {
+		return n.regex.MatchString(value)
+	}
+	return value == n.literal.value.(string)
+}
+
+func compare[T cmp.Ordered](value T, other T, op tokenKind) bool {
+	switch op {
+	case tokenEq:
+		return value == other
+	case tokenNe:
+		return value != other
+	case tokenGt:
+		return value > other
+	case tokenGe:
+		return value >= other
+	case tokenLt:
+		return value < other
+	case tokenLe:
+		return value <= other
+	}
+	return false
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorAt(closing.pos, "expected ) to close the ( at position %d, got %s", t.pos+1,
+				describe(closing))
+		}
+		return inner, nil
+	case tokenIdent:
+		f, ok := fields[t.text]
+		if !ok {
+			return nil, errorAt(t.pos, "unknown field %s, expected one of %s", t.text, fieldNames())
+		}
+		if !isComparison(p.peek().kind) {
+			return &fieldNode{field: f}, nil
+		}
+		return p.parseComparison(t, f)
+	default:
+		return nil, errorAt(t.pos, "expected a field, ! or ( but got %s", describe(t))
+	}
+}
+
+func (p *parser) parseComparison(fieldToken token, f field) (node, error) {
+	op := p.next()
+	valueToken := p.next()
+	lit, err := parseLiteral(op, valueToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canCompare(f.kind, lit.kind, op.kind) {
+		return nil, errorAt(op.pos, "cannot compare the %s field %s with the %s %s using %s%s", f.kind,
+			fieldToken.text, lit.kind, describe(valueToken), op.text, comparisonHint(f.kind))
+	}
+
+	comparison := &comparisonNode{field: f, op: op.kind, literal: lit}
+	if op.kind == tokenMatch || op.kind == tokenNotMatch {
+		comparison.regex, err = regexp.Compile(lit.value.(string))
+		if err != nil {
+			return nil, errorAt(valueToken.pos, "invalid regular expression %s: %v", describe(valueToken), err)
+		}
+	}
+	return comparison, nil
+}
+
+func parseLiteral(op token, t token) (literal, error) {
+	switch t.kind {
+	case tokenString:
+		return literal{kind: kindString, value: t.text}, nil
+	case tokenNumber:
+		n, err := strconv.Atoi(t.text)
+		if err != nil {
+			return literal{}, errorAt(t.pos, "invalid number %s", t.text)
+		}
+		return literal{kind: kindNumber, value: n}, nil
+	case tokenDuration:
+		n, err := strconv.Atoi(t.text[:len(t.text)-1])
+		if err != nil {
+			return literal{}, errorAt(t.pos, "invalid duration %s", t.text)
+		}
+		return literal{kind: kindDuration, value: time.Duration(n) * durationUnitLengths[t.text[len(t.text)-1]]}, nil
+	case tokenIdent:
+		// NOTE: Bare words other than true and false read as strings, eg state == open.
+		if t.text == "true" || t.text == "false" {
+			return literal{kind: kindBool, value: t.text == "true"}, nil
+		}
+		return literal{kind: kindString, value: t.text}, nil
+	default:
+		return literal{}, errorAt(t.pos, "expected a value after %s but got %s", op.text, describe(t))
+	}
+}
+
+func isComparison(kind tokenKind) bool {
+	return kind >= tokenEq && kind <= tokenNotMatch
+}
+
+func isOrdering(kind tokenKind) bool {
+	return kind >= tokenEq && kind <= tokenLe
+}
+
+func canCompare(fieldKind valueKind, literalKind valueKind, op tokenKind) bool {
+	switch fieldKind {
+	case kindString:
+		return literalKind == kindString && (op == tokenEq || op == tokenNe || op == tokenMatch || op == tokenNotMatch)
+	case kindBool:
+		return literalKind == kindBool && (op == tokenEq || op == tokenNe)
+	case kindNumber, kindDuration:
+		return literalKind == fieldKind && isOrdering(op)
+	case kindList:
+		return (literalKind == kindNumber && isOrdering(op)) ||
+			(literalKind == kindString && (op == tokenEq || op == tokenNe || op == tokenMatch || op == tokenNotMatch))
+	}
+	return false
+}
+
+func comparisonHint(kind valueKind) string {
+	switch kind {
+	case kindString:
+		return ", strings only support ==, !=, ~ and !~"
+	case kindBool:
+		return ", booleans only support == and != with true or false"
+	case kindDuration:
+		return ", durations take a unit such as 30m, 12h, 7d or 2w"
+	case kindList:
+		return ", lists compare their length with numbers and their elements with strings"
+	}
+	return ""
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "the end of the filter"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return t.text
+}
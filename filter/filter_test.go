@@ -0,0 +1,175 @@
+package filter
+
+import (
+	"github.com/dhruv1397/prm/types"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func newGithubPR() *types.PullRequest {
+	return &types.PullRequest{
+		Number:          5,
+		Title:           "Add the api client",
+		SCMProviderType: "github",
+		State:           "open",
+		Mergeable:       "true",
+		Approved:        []string{"alice", "bob"},
+		Labels:          []string{"bug", "ui"},
+		Repo:            "org/api-client",
+		Author:          "carol",
+		Created:         now.Add(-10 * 24 * time.Hour).UnixMilli(),
+		Updated:         now.Add(-2 * time.Hour).UnixMilli(),
+	}
+}
+
+// newGitlabPR is a PR of a provider which reports neither the draft flag, the author nor the creation time, and
+// cannot tell whether it can be merged.
+func newGitlabPR() *types.PullRequest {
+	return &types.PullRequest{
+		Number:          7,
+		SCMProviderType: "gitlab",
+		State:           "open",
+		Mergeable:       "-",
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		pr         *types.PullRequest
+		want       bool
+	}{
+		{"not binds tighter than and", `!draft && mergeable`, newGithubPR(), true},
+		{"not applies to the parentheses", `!(draft || mergeable)`, newGithubPR(), false},
+		{"double not", `!!draft`, newGithubPR(), false},
+		{"and binds tighter than or on the left", `draft && mergeable || number == 5`, newGithubPR(), true},
+		{"and binds tighter than or on the right", `number == 5 || draft && mergeable`, newGithubPR(), true},
+		{"parentheses override the precedence", `draft && (mergeable || number == 5)`, newGithubPR(), false},
+		{"or of negations", `!mergeable || !draft`, newGithubPR(), true},
+
+		{"greater or equal without spaces", `approved>=2`, newGithubPR(), true},
+		{"greater than", `approved > 2`, newGithubPR(), false},
+		{"less or equal", `number <= 5`, newGithubPR(), true},
+		{"less than", `number < 5`, newGithubPR(), false},
+
+		{"days", `age > 7d`, newGithubPR(), true},
+		{"weeks", `age > 2w`, newGithubPR(), false},
+		{"weeks upper bound", `age < 2w`, newGithubPR(), true},
+		{"hours", `idle >= 2h`, newGithubPR(), true},
+		{"minutes", `idle < 30m`, newGithubPR(), false},
+
+		{"regex matches anywhere", `repo ~ "api-.*"`, newGithubPR(), true},
+		{"anchored regex", `repo ~ "^api"`, newGithubPR(), false},
+		{"regex does not match", `repo !~ "^org/"`, newGithubPR(), false},
+		{"regex with a backslash", `title ~ "\bapi\b"`, newGithubPR(), true},
+		{"regex matches a list element", `labels ~ "^b"`, newGithubPR(), true},
+		{"regex matches no list element", `labels !~ "^x"`, newGithubPR(), true},
+
+		{"list length equals a number", `labels == 2`, newGithubPR(), true},
+		{"list length compared with a number", `approved > 1`, newGithubPR(), true},
+		{"list contains a string", `labels == "bug"`, newGithubPR(), true},
+		{"list does not contain a string", `labels != "bug"`, newGithubPR(), false},
+		{"quoted number is a string", `labels == "2"`, newGithubPR(), false},
+		{"empty list is unset", `pending_reviewers`, newGithubPR(), false},
+
+		{"bare word is a string", `state == open`, newGithubPR(), true},
+		{"single quotes", `author == 'carol'`, newGithubPR(), true},
+		{"boolean literal", `mergeable == false`, newGithubPR(), false},
+
+		{"unknown field on its own", `draft`, newGitlabPR(), false},
+		{"negated unknown field", `!draft`, newGitlabPR(), false},
+		{"unknown string field", `author != "bob"`, newGitlabPR(), false},
+		{"unknown duration field", `age < 7d`, newGitlabPR(), false},
+		{"unknown mergeability", `!mergeable`, newGitlabPR(), false},
+		{"or with a known side holds", `!draft || state == open`, newGitlabPR(), true},
+		{"and with a false side is false", `!(draft && state == closed)`, newGitlabPR(), true},
+		{"and with an unknown side", `!draft && state == open`, newGitlabPR(), false},
+		{"unknown checks", `checks.failing == 0`, newGithubPR(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.expression, err)
+			}
+			got, known := f.root.eval(tt.pr, now)
+			if got = known && got; got != tt.want {
+				t.Errorf("%q matched %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []tokenKind
+	}{
+		{`a>=1`, []tokenKind{tokenIdent, tokenGe, tokenNumber, tokenEOF}},
+		{`a>1`, []tokenKind{tokenIdent, tokenGt, tokenNumber, tokenEOF}},
+		{`a<=1`, []tokenKind{tokenIdent, tokenLe, tokenNumber, tokenEOF}},
+		{`a!=b`, []tokenKind{tokenIdent, tokenNe, tokenIdent, tokenEOF}},
+		{`a!~"b"`, []tokenKind{tokenIdent, tokenNotMatch, tokenString, tokenEOF}},
+		{`!a`, []tokenKind{tokenNot, tokenIdent, tokenEOF}},
+		{`a > 7d`, []tokenKind{tokenIdent, tokenGt, tokenDuration, tokenEOF}},
+		{`checks.failing`, []tokenKind{tokenIdent, tokenEOF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			tokens, err := tokenize(tt.expression)
+			if err != nil {
+				t.Fatalf("tokenize(%q) returned error: %v", tt.expression, err)
+			}
+			if len(tokens) != len(tt.want) {
+				t.Fatalf("tokenize(%q) returned %d tokens, want %d", tt.expression, len(tokens), len(tt.want))
+			}
+			for i, token := range tokens {
+				if token.kind != tt.want[i] {
+					t.Errorf("token %d of %q is %q of kind %d, want kind %d", i, tt.expression, token.text, token.kind,
+						tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{`draft &&`, `position 9: expected a field, ! or ( but got the end of the filter`},
+		{`)`, `position 1: expected a field, ! or ( but got )`},
+		{`(draft`, `position 7: expected ) to close the ( at position 1, got the end of the filter`},
+		{`draft mergeable`, `position 7: expected && or || before mergeable`},
+		{`number >`, `position 9: expected a value after > but got the end of the filter`},
+		{`number > = 2`, `position 10: unexpected character '='`},
+		{`number == 1 # 2`, `position 13: unexpected character '#'`},
+		{`"abc`, `position 1: unterminated string`},
+		{`number == 7x`, `position 11: invalid number "7x", durations take one of the units m, h, d, w`},
+		{`age > 7`, `position 5: cannot compare the duration field age with the number 7 using >, durations take a ` +
+			`unit such as 30m, 12h, 7d or 2w`},
+		{`title > "x"`, `position 7: cannot compare the string field title with the string "x" using >, strings only ` +
+			`support ==, !=, ~ and !~`},
+		{`draft == 1`, `position 7: cannot compare the boolean field draft with the number 1 using ==, booleans only ` +
+			`support == and != with true or false`},
+		{`labels > "a"`, `position 8: cannot compare the list field labels with the string "a" using >, lists compare ` +
+			`their length with numbers and their elements with strings`},
+		{`title ~ "("`, "position 9: invalid regular expression \"(\": error parsing regexp: missing closing ): `(`"},
+		{`foo == 1`, `position 1: unknown field foo, expected one of ` + fieldNames()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := Parse(tt.expression)
+			if err == nil {
+				t.Fatalf("Parse(%q) returned no error, want %q", tt.expression, tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Parse(%q) returned error %q, want %q", tt.expression, err.Error(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDuration
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenEq
+	tokenNe
+	tokenGt
+	tokenGe
+	tokenLt
+	tokenLe
+	tokenMatch
+	tokenNotMatch
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the offset of the token in the expression.
+	pos int
+}
+
+// operators are matched longest first so that eg >= is not read as > followed by =.
+var operators = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokenAnd},
+	{"||", tokenOr},
+	{"==", tokenEq},
+	{"!=", tokenNe},
+	{"!~", tokenNotMatch},
+	{">=", tokenGe},
+	{"<=", tokenLe},
+	{">", tokenGt},
+	{"<", tokenLt},
+	{"~", tokenMatch},
+	{"!", tokenNot},
+	{"(", tokenLParen},
+	{")", tokenRParen},
+}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(expression)
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '"' || r == '\'':
+			text, end, err := readString(runes, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			pos = end
+		case unicode.IsDigit(r):
+			end := pos
+			for end < len(runes) && unicode.IsDigit(runes[end]) {
+				end++
+			}
+			kind := tokenNumber
+			if end < len(runes) && strings.ContainsRune(durationUnits, runes[end]) {
+				kind = tokenDuration
+				end++
+			}
+			if end < len(runes) && isIdentRune(runes[end]) {
+				return nil, errorAt(pos, "invalid number %q, durations take one of the units %s",
+					readWhile(runes, pos, isIdentRune), strings.Join(strings.Split(durationUnits, ""), ", "))
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[pos:end]), pos: pos})
+			pos = end
+		case unicode.IsLetter(r) || r == '_':
+			text := readWhile(runes, pos, isIdentRune)
+			tokens = append(tokens, token{kind: tokenIdent, text: text, pos: pos})
+			pos += len([]rune(text))
+		default:
+			kind, text := readOperator(runes[pos:])
+			if text == "" {
+				return nil, errorAt(pos, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: pos})
+			pos += len(text)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// readString reads the quoted string starting at pos. Only the quote and backslash can be escaped, other backslashes
+// are kept as they are so that regular expressions such as "\d+" read naturally.
+func readString(runes []rune, pos int) (string, int, error) {
+	quote := runes[pos]
+	var text strings.Builder
+	for i := pos + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == quote || runes[i+1] == '\\'):
+			text.WriteRune(runes[i+1])
+			i++
+		case runes[i] == quote:
+			return text.String(), i + 1, nil
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, errorAt(pos, "unterminated string")
+}
+
+func readOperator(runes []rune) (tokenKind, string) {
+	for _, operator := range operators {
+		if strings.HasPrefix(string(runes), operator.text) {
+			return operator.kind, operator.text
+		}
+	}
+	return tokenEOF, ""
+}
+
+func readWhile(runes []rune, pos int, fn func(rune) bool) string {
+	end := pos
+	for end < len(runes) && fn(runes[end]) {
+		end++
+	}
+	return string(runes[pos:end])
+}
+
+// isIdentRune allows dots so that nested fields such as checks.failing read as one identifier.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+func errorAt(pos int, format string, args ...any) error {
+	return fmt.Errorf("position %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
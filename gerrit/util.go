@@ -0,0 +1,45 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NOTE: Gerrit prefixes every JSON response with a magic line to prevent XSSI, it has to be stripped before parsing.
+var xssiPrefix = []byte(")]}'")
+
+func Get(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error while forming request: %w", err)
+	}
+	r.Header.Set("Accept", "application/json")
+	r.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(pat)))
+
+	response, err := client.Do(r)
+	if err != nil {
+		return fmt.Errorf("error while executing request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error while parsing response body: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected response status %s: %s", response.Status, string(body))
+	}
+	body = bytes.TrimPrefix(body, xssiPrefix)
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(body, responseDTO)
+	if err != nil {
+		return fmt.Errorf("error while parsing response: %w", err)
+	}
+	return nil
+}
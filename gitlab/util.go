@@ -0,0 +1,70 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+func Get(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) error {
+	_, err := do(ctx, client, http.MethodGet, pat, url, responseDTO)
+	return err
+}
+
+// GetPage behaves like Get and additionally returns the next page number advertised by the X-Next-Page
+// header, or 0 when the current page is the last one.
+func GetPage(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) (int, error) {
+	header, err := do(ctx, client, http.MethodGet, pat, url, responseDTO)
+	if err != nil {
+		return 0, err
+	}
+	nextPage := header.Get("X-Next-Page")
+	if nextPage == "" {
+		return 0, nil
+	}
+	page, err := strconv.Atoi(nextPage)
+	if err != nil {
+		return 0, fmt.Errorf("error while parsing next page %s: %w", nextPage, err)
+	}
+	return page, nil
+}
+
+func do(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	pat string,
+	url string,
+	responseDTO any,
+) (http.Header, error) {
+	r, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while forming request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("PRIVATE-TOKEN", pat)
+
+	response, err := client.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response body: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected response status %s: %s", response.Status, string(body))
+	}
+	if len(body) == 0 {
+		return response.Header, nil
+	}
+	err = json.Unmarshal(body, responseDTO)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response: %w", err)
+	}
+	return response.Header, nil
+}
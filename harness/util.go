@@ -5,12 +5,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/dhruv1397/prm/transport"
 	"io"
 	"net/http"
+	"strconv"
+)
+
+const (
+	// PageLimit is the largest page size accepted by the Harness list APIs.
+	PageLimit = 100
+
+	// NoNextPage is returned by NextPage once the last page has been read.
+	NoNextPage = -1
 )
 
 func Get(ctx context.Context, client *http.Client, pat string, url string, responseDTO any) error {
-	return do(ctx, client, http.MethodGet, pat, url, nil, responseDTO)
+	_, err := do(ctx, client, http.MethodGet, pat, url, nil, responseDTO)
+	return err
+}
+
+// GetPage behaves like Get and additionally returns the number of the page following the one requested, or
+// NoNextPage when there is none. The requested page is needed for APIs that only report the total element count.
+func GetPage(ctx context.Context, client *http.Client, pat string, url string, page int, responseDTO any) (int, error) {
+	header, err := do(ctx, client, http.MethodGet, pat, url, nil, responseDTO)
+	if err != nil {
+		return NoNextPage, err
+	}
+	return nextPage(header, page), nil
 }
 
 func Post(ctx context.Context, client *http.Client, pat string, url string, reqBody, responseDTO any) error {
@@ -18,7 +39,35 @@ func Post(ctx context.Context, client *http.Client, pat string, url string, reqB
 	if err != nil {
 		return fmt.Errorf("error while marshalling request body: %w", err)
 	}
-	return do(ctx, client, http.MethodPost, pat, url, bytes.NewBuffer(bodyBytes), responseDTO)
+	_, err = do(ctx, client, http.MethodPost, pat, url, bytes.NewBuffer(bodyBytes), responseDTO)
+	return err
+}
+
+// nextPage reads the paging headers of a list response. The Code APIs advertise the next page directly, while the
+// platform APIs only report the page number, page size and total number of elements.
+func nextPage(header http.Header, page int) int {
+	if header == nil {
+		return NoNextPage
+	}
+	if next := header.Get("x-next-page"); next != "" {
+		nextPageNumber, err := strconv.Atoi(next)
+		if err != nil || nextPageNumber <= page {
+			return NoNextPage
+		}
+		return nextPageNumber
+	}
+	total, totalErr := strconv.Atoi(header.Get("X-Total-Elements"))
+	pageSize, pageSizeErr := strconv.Atoi(header.Get("X-Page-Size"))
+	if totalErr != nil || pageSizeErr != nil || pageSize <= 0 {
+		return NoNextPage
+	}
+	if pageNumber, err := strconv.Atoi(header.Get("X-Page-Number")); err == nil {
+		page = pageNumber
+	}
+	if (page+1)*pageSize >= total {
+		return NoNextPage
+	}
+	return page + 1
 }
 
 func do(
@@ -29,29 +78,31 @@ func do(
 	url string,
 	reqBody io.Reader,
 	responseDTO any,
-) error {
+) (http.Header, error) {
 	r, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("error while forming request: %w", err)
+		return nil, fmt.Errorf("error while forming request: %w", err)
 	}
 	r.Header.Set("Content-Type", "application/json")
 	r.Header.Set("x-api-key", pat)
+	// NOTE: prm only sends read-only requests, POSTs included as they are merge dry runs, so all are safe to retry.
+	transport.MarkIdempotent(r)
 
 	response, err := client.Do(r)
 	if err != nil {
-		return fmt.Errorf("error while executing request: %w", err)
+		return nil, fmt.Errorf("error while executing request: %w", err)
 	}
 	defer response.Body.Close()
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("error while parsing response body: %w", err)
+		return nil, fmt.Errorf("error while parsing response body: %w", err)
 	}
 	if response.StatusCode == http.StatusNotFound || len(body) == 0 {
-		return nil
+		return nil, nil
 	}
 	err = json.Unmarshal(body, responseDTO)
 	if err != nil {
-		return fmt.Errorf("error while parsing response: %w", err)
+		return nil, fmt.Errorf("error while parsing response: %w", err)
 	}
-	return nil
+	return response.Header, nil
 }
@@ -0,0 +1,174 @@
+package keyring
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/util"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	keyringFileName  = "keyring"
+	backupFileSuffix = ".bak"
+
+	// PassphraseEnv holds the passphrase of the file keyring, for machines where prompting is not possible.
+	PassphraseEnv = "PRM_KEYRING_PASSPHRASE"
+)
+
+// fileKeyring keeps the secrets in a file encrypted with a passphrase. It is the fallback for machines without an
+// OS keyring, eg headless Linux.
+type fileKeyring struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+	secrets    map[string]string
+}
+
+var _ Keyring = (*fileKeyring)(nil)
+
+func newFileKeyring() (*fileKeyring, error) {
+	path := getFilePath()
+	if path == "" {
+		configDir, err := util.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(configDir, keyringFileName)
+	}
+	return &fileKeyring{path: path}, nil
+}
+
+func (f *fileKeyring) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	secret, ok := f.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *fileKeyring) Set(key string, secret string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+	if current, ok := f.secrets[key]; ok && current == secret {
+		return nil
+	}
+	f.secrets[key] = secret
+	return f.save()
+}
+
+func (f *fileKeyring) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+	if _, ok := f.secrets[key]; !ok {
+		return nil
+	}
+	delete(f.secrets, key)
+	return f.save()
+}
+
+// load decrypts the keyring file on first use. The passphrase is only asked for once the file exists, or when the
+// first secret is written.
+func (f *fileKeyring) load() error {
+	if f.secrets != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		f.secrets = map[string]string{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading keyring file %s: %w", f.path, err)
+	}
+	if !encryption.IsEncrypted(data) {
+		backupPath := f.path + backupFileSuffix
+		backup, err := os.ReadFile(backupPath)
+		if err != nil || !encryption.IsEncrypted(backup) {
+			return fmt.Errorf("keyring file %s is corrupt and there is no valid backup at %s", f.path, backupPath)
+		}
+		fmt.Fprintf(os.Stderr, "Keyring file %s is corrupt, read the PATs from its backup %s instead\n", f.path,
+			backupPath)
+		data = backup
+	}
+
+	passphrase, err := encryption.ReadPassphrase(PassphraseEnv, "Enter the passphrase of the prm keyring file:", false)
+	if err != nil {
+		return err
+	}
+	plaintext, err := encryption.Decrypt(data, passphrase)
+	if err != nil {
+		return fmt.Errorf("error decrypting keyring file %s: %w", f.path, err)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("error deserialising keyring file %s: %w", f.path, err)
+	}
+	f.passphrase = passphrase
+	f.secrets = secrets
+	return nil
+}
+
+func (f *fileKeyring) save() error {
+	if f.passphrase == "" {
+		passphrase, err := encryption.ReadPassphrase(PassphraseEnv,
+			"No OS keyring is available, enter a passphrase to encrypt the PATs with:", true)
+		if err != nil {
+			return err
+		}
+		f.passphrase = passphrase
+	}
+
+	plaintext, err := json.Marshal(f.secrets)
+	if err != nil {
+		return fmt.Errorf("error serialising keyring: %w", err)
+	}
+	data, err := encryption.Encrypt(plaintext, f.passphrase)
+	if err != nil {
+		return fmt.Errorf("error encrypting keyring: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("error creating directory of keyring file %s: %w", f.path, err)
+	}
+	// NOTE: The file holds every PAT, so like the config it is replaced atomically and the previous one is kept as a
+	// backup.
+	if current, err := os.ReadFile(f.path); err == nil && encryption.IsEncrypted(current) {
+		if err := util.WriteFileAtomic(f.path+backupFileSuffix, current); err != nil {
+			return fmt.Errorf("error backing up keyring file %s: %w", f.path, err)
+		}
+	}
+	if err := util.WriteFileAtomic(f.path, data); err != nil {
+		return fmt.Errorf("error writing keyring file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+func (f *fileKeyring) purge() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.secrets = nil
+	for _, path := range []string{f.path, f.path + backupFileSuffix} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error purging keyring file %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// service is the name under which the secrets of prm are grouped in the OS keyring.
+	service = "prm"
+
+	// BackendEnv forces a keyring backend, either BackendSystem or BackendFile, instead of detecting one.
+	BackendEnv = "PRM_KEYRING_BACKEND"
+
+	BackendSystem = "system"
+	BackendFile   = "file"
+)
+
+// ErrNotFound is returned by Get when no secret is stored under the key.
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Keyring stores secrets, eg PATs, outside the prm config file.
+type Keyring interface {
+	Get(key string) (string, error)
+	Set(key string, secret string) error
+	Delete(key string) error
+}
+
+var (
+	defaultKeyring    Keyring
+	defaultKeyringErr error
+	defaultKeyringMu  sync.Mutex
+
+	filePath   string
+	filePathMu sync.Mutex
+)
+
+// SetFilePath sets where the file keyring is kept instead of the prm config directory, eg next to a config given with
+// --config. It has to be called before the keyring is opened.
+func SetFilePath(path string) {
+	filePathMu.Lock()
+	defer filePathMu.Unlock()
+
+	filePath = path
+}
+
+func getFilePath() string {
+	filePathMu.Lock()
+	defer filePathMu.Unlock()
+
+	return filePath
+}
+
+// Default returns the keyring of the OS when one is reachable, and the encrypted file keyring otherwise, eg on
+// headless Linux. The keyring is opened once per process.
+func Default() (Keyring, error) {
+	defaultKeyringMu.Lock()
+	defer defaultKeyringMu.Unlock()
+
+	if defaultKeyring == nil && defaultKeyringErr == nil {
+		defaultKeyring, defaultKeyringErr = open(os.Getenv(BackendEnv))
+	}
+	return defaultKeyring, defaultKeyringErr
+}
+
+// Purge deletes the given keys from the OS keyring, if it is in use, and removes the file keyring altogether. Unlike
+// Delete it never asks for the passphrase of the file keyring.
+func Purge(keys []string) error {
+	kr, err := Default()
+	if err != nil {
+		return err
+	}
+	if systemKeyring, ok := kr.(*systemKeyring); ok {
+		for _, key := range keys {
+			if err := systemKeyring.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	fileKeyring, ok := kr.(*fileKeyring)
+	if !ok {
+		fileKeyring, err = newFileKeyring()
+		if err != nil {
+			return err
+		}
+	}
+	return fileKeyring.purge()
+}
+
+func open(backend string) (Keyring, error) {
+	switch backend {
+	case BackendSystem:
+		systemKeyring, ok := newSystemKeyring()
+		if !ok {
+			return nil, fmt.Errorf("no OS keyring is available")
+		}
+		return systemKeyring, nil
+	case BackendFile:
+		return newFileKeyring()
+	case "":
+		if systemKeyring, ok := newSystemKeyring(); ok {
+			return systemKeyring, nil
+		}
+		return newFileKeyring()
+	default:
+		return nil, fmt.Errorf("unknown keyring backend %s set in %s", backend, BackendEnv)
+	}
+}
@@ -0,0 +1,147 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// systemKeyring talks to the keyring of the OS through its command line tools, the Keychain through security on
+// macOS and the Secret Service through secret-tool on Linux.
+type systemKeyring struct {
+	commands systemCommands
+}
+
+type systemCommands interface {
+	get(key string) *exec.Cmd
+	set(key string, secret string) *exec.Cmd
+	delete(key string) *exec.Cmd
+	// isNotFound reports whether a failed command failed only because the key does not exist.
+	isNotFound(exitCode int, stderr string) bool
+}
+
+var _ Keyring = (*systemKeyring)(nil)
+
+func newSystemKeyring() (*systemKeyring, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return &systemKeyring{commands: keychainCommands{}}, true
+		}
+	case "linux":
+		// NOTE: secret-tool needs a D-Bus session to reach the Secret Service, which headless machines lack.
+		if _, err := exec.LookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			return &systemKeyring{commands: secretServiceCommands{}}, true
+		}
+	}
+	return nil, false
+}
+
+func (s *systemKeyring) Get(key string) (string, error) {
+	output, _, err := s.run(s.commands.get(key))
+	if errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading %s from the OS keyring: %w", key, err)
+	}
+	return strings.TrimSuffix(output, "\n"), nil
+}
+
+func (s *systemKeyring) Set(key string, secret string) error {
+	// NOTE: The interactive mode of security exits successfully even when the command it runs fails, and only reports
+	// the failure on stderr.
+	_, message, err := s.run(s.commands.set(key, secret))
+	if err == nil && message != "" {
+		err = errors.New(message)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing %s to the OS keyring: %w", key, err)
+	}
+	return nil
+}
+
+func (s *systemKeyring) Delete(key string) error {
+	_, _, err := s.run(s.commands.delete(key))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("error deleting %s from the OS keyring: %w", key, err)
+	}
+	return nil
+}
+
+// run executes the command and returns its output and error output, or ErrNotFound if it failed because the key does
+// not exist.
+func (s *systemKeyring) run(cmd *exec.Cmd) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && s.commands.isNotFound(exitErr.ExitCode(), message) {
+			return "", message, ErrNotFound
+		}
+		if message != "" {
+			return "", message, fmt.Errorf("%w: %s", err, message)
+		}
+		return "", message, err
+	}
+	return stdout.String(), strings.TrimSpace(stderr.String()), nil
+}
+
+type keychainCommands struct{}
+
+// keychainItemNotFound is the exit status of security when the item does not exist.
+const keychainItemNotFound = 44
+
+func (keychainCommands) get(key string) *exec.Cmd {
+	return exec.Command("security", "find-generic-password", "-s", service, "-a", key, "-w")
+}
+
+func (keychainCommands) set(key string, secret string) *exec.Cmd {
+	// NOTE: security only accepts the password as an argument or from a prompt on the terminal. Arguments are visible to
+	// other users through ps, so the command is fed to the interactive mode of security on stdin instead.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quoteKeychainArg(service), quoteKeychainArg(key), quoteKeychainArg(secret)))
+	return cmd
+}
+
+// quoteKeychainArg quotes the argument for the interactive mode of security, which splits its input like a shell.
+func quoteKeychainArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+func (keychainCommands) delete(key string) *exec.Cmd {
+	return exec.Command("security", "delete-generic-password", "-s", service, "-a", key)
+}
+
+func (keychainCommands) isNotFound(exitCode int, _ string) bool {
+	return exitCode == keychainItemNotFound
+}
+
+type secretServiceCommands struct{}
+
+func (secretServiceCommands) get(key string) *exec.Cmd {
+	return exec.Command("secret-tool", "lookup", "service", service, "account", key)
+}
+
+func (secretServiceCommands) set(key string, secret string) *exec.Cmd {
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+key, "service", service, "account", key)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd
+}
+
+func (secretServiceCommands) delete(key string) *exec.Cmd {
+	return exec.Command("secret-tool", "clear", "service", service, "account", key)
+}
+
+func (secretServiceCommands) isNotFound(exitCode int, stderr string) bool {
+	// NOTE: secret-tool lookup exits with 1 and prints nothing when there is no matching secret.
+	return exitCode == 1 && stderr == ""
+}
@@ -0,0 +1,311 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/azure"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const azurePageLimit = 100
+
+// NOTE: See https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-reviewers for vote values.
+const (
+	azureVoteApproved                = 10
+	azureVoteApprovedWithSuggestions = 5
+	azureVoteWaitingForAuthor        = -5
+	azureVoteRejected                = -10
+	azureVoteNone                    = 0
+)
+
+type AzurePRClient struct {
+	httpClient   *http.Client
+	host         string
+	user         *types.User
+	repos        []*types.Repo
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*AzurePRClient)(nil)
+
+func NewAzurePRClient(httpClient *http.Client, host string, user *types.User, repos []*types.Repo, providerName string, concurrency int) (*AzurePRClient, error) {
+	return &AzurePRClient{
+		httpClient:   httpClient,
+		host:         host,
+		user:         user,
+		repos:        repos,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (a *AzurePRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var allPullRequests []*types.PullRequestResponse
+	userCriteria, err := getAzureUserCriteria(role)
+	if err != nil {
+		return allPullRequests, err
+	}
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	errChan := make(chan error)
+	prChan := make(chan *types.PullRequestResponse)
+
+	// NOTE: The repos are started in the background, no more than the concurrency at once, so that their results are
+	// collected meanwhile. The PRs of all the repos share a second limiter, as a repo holding its slot while waiting on
+	// its PRs would otherwise starve them.
+	repoLimiter := transport.NewLimiter(a.concurrency)
+	prLimiter := transport.NewLimiter(a.concurrency)
+	go func() {
+		for _, repo := range a.repos {
+			if err := repoLimiter.Acquire(ctx); err != nil {
+				errChan <- err
+				break
+			}
+			wg.Add(1)
+			go func(repo *types.Repo) {
+				defer wg.Done()
+				defer repoLimiter.Release()
+
+				prs, err := a.getPRs(ctx, repo, state, userCriteria)
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				var prWg sync.WaitGroup
+				for _, pr := range prs {
+					if err := prLimiter.Acquire(ctx); err != nil {
+						errChan <- err
+						break
+					}
+					prWg.Add(1)
+					go func(pr *types.AzurePullRequest) {
+						defer prWg.Done()
+						defer prLimiter.Release()
+
+						prResponse, err := a.getPRDetails(ctx, repo, pr, transformationFn)
+						if err != nil {
+							errChan <- err
+							return
+						}
+
+						prChan <- prResponse
+					}(pr)
+				}
+
+				prWg.Wait()
+			}(repo)
+		}
+		wg.Wait()
+		close(prChan)
+		close(errChan)
+	}()
+
+	var errs []error
+
+	for prChan != nil || errChan != nil {
+		select {
+		case resp, ok := <-prChan:
+			if !ok {
+				prChan = nil
+			} else {
+				prMutex.Lock()
+				allPullRequests = append(allPullRequests, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errChan:
+			if !ok {
+				errChan = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return allPullRequests, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return allPullRequests, nil
+}
+
+func (a *AzurePRClient) getRepoURL(repo *types.Repo) string {
+	return fmt.Sprintf("%s/%s/_apis/git/repositories/%s", azure.OrgURL(a.host, repo.OrgIdentifier),
+		url.PathEscape(repo.ProjectIdentifier), url.PathEscape(repo.RepoIdentifier))
+}
+
+func (a *AzurePRClient) getAzurePRURL(prNumber int, repo *types.Repo) string {
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%d", azure.OrgURL(a.host, repo.OrgIdentifier),
+		url.PathEscape(repo.ProjectIdentifier), url.PathEscape(repo.RepoIdentifier), prNumber)
+}
+
+// getAzureUserCriteria returns the search criteria matching the PRs in which the user has the role, the PRs matching
+// any of them are listed.
+func getAzureUserCriteria(role string) ([]string, error) {
+	switch role {
+	case RoleAuthor:
+		return []string{"creatorId"}, nil
+	case RoleReviewer:
+		return []string{"reviewerId"}, nil
+	case RoleInvolved:
+		return []string{"creatorId", "reviewerId"}, nil
+	default:
+		return nil, unsupportedRoleError("azure", role)
+	}
+}
+
+func (a *AzurePRClient) getPRs(
+	ctx context.Context,
+	repo *types.Repo,
+	state string,
+	userCriteria []string,
+) ([]*types.AzurePullRequest, error) {
+	var azureStatus = "all"
+	if state == "open" {
+		azureStatus = "active"
+	} else if state == "merged" {
+		azureStatus = "completed"
+	} else if state == "closed" {
+		azureStatus = "abandoned"
+	}
+
+	var prs = make([]*types.AzurePullRequest, 0)
+	seen := map[int]bool{}
+	for _, userCriterion := range userCriteria {
+		for skip := 0; ; skip += azurePageLimit {
+			apiURL := fmt.Sprintf("%s/pullrequests?searchCriteria.%s=%s&searchCriteria.status=%s"+
+				"&$top=%d&$skip=%d&api-version=%s", a.getRepoURL(repo), userCriterion, a.user.AccountID, azureStatus,
+				azurePageLimit, skip, azure.APIVersion)
+			responseObj := &types.AzurePullRequestList{}
+			err := azure.Get(ctx, a.httpClient, a.user.PAT, apiURL, responseObj)
+			if err != nil {
+				return prs, fmt.Errorf("error fetching PRs for repo %s: %w", repo.RepoIdentifier, err)
+			}
+			for _, pr := range responseObj.Value {
+				if !seen[pr.PullRequestID] {
+					seen[pr.PullRequestID] = true
+					prs = append(prs, pr)
+				}
+			}
+			if len(responseObj.Value) < azurePageLimit {
+				break
+			}
+		}
+	}
+	return prs, nil
+}
+
+func (a *AzurePRClient) getPRDetails(
+	ctx context.Context,
+	repo *types.Repo,
+	pr *types.AzurePullRequest,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	prURL := a.getAzurePRURL(pr.PullRequestID, repo)
+
+	threads := &types.AzureThreadList{}
+	apiURL := fmt.Sprintf("%s/pullRequests/%d/threads?api-version=%s", a.getRepoURL(repo), pr.PullRequestID,
+		azure.APIVersion)
+	err := azure.Get(ctx, a.httpClient, a.user.PAT, apiURL, threads)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching PR threads for %s: %w", prURL, err)
+	}
+
+	approvedMap := map[string]bool{}
+	commentedMap := map[string]bool{}
+	changesRequestedMap := map[string]bool{}
+	pendingReviewers := make([]string, 0)
+	var myVote *int
+	commentedByMe := false
+
+	// NOTE: The votes are the current ones of each reviewer, branch policies may reset them on new commits. Reviewers
+	// which have not voted yet, users and groups alike, are pending.
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.ID == a.user.AccountID {
+			myVote = &reviewer.Vote
+		}
+		switch reviewer.Vote {
+		case azureVoteApproved:
+			approvedMap[reviewer.DisplayName] = true
+		case azureVoteApprovedWithSuggestions:
+			approvedMap[reviewer.DisplayName] = true
+			commentedMap[reviewer.DisplayName] = true
+		case azureVoteWaitingForAuthor, azureVoteRejected:
+			changesRequestedMap[reviewer.DisplayName] = true
+		case azureVoteNone:
+			pendingReviewers = append(pendingReviewers, reviewer.DisplayName)
+		}
+	}
+
+	for _, thread := range threads.Value {
+		for _, comment := range thread.Comments {
+			if comment.CommentType == "text" {
+				commentedMap[comment.Author.DisplayName] = true
+				if comment.Author.ID == a.user.AccountID {
+					commentedByMe = true
+				}
+			}
+		}
+	}
+
+	myReview := ""
+	if myVote != nil {
+		myReview = getMyReview(*myVote == azureVoteApproved || *myVote == azureVoteApprovedWithSuggestions,
+			*myVote == azureVoteWaitingForAuthor || *myVote == azureVoteRejected, commentedByMe, true)
+	} else if commentedByMe {
+		myReview = myReviewCommented
+	}
+
+	state := "open"
+	if pr.Status == "completed" {
+		state = "merged"
+	} else if pr.Status == "abandoned" {
+		state = "closed"
+	}
+
+	mergeable := "-"
+	if state == "open" {
+		mergeable = "false"
+		if pr.MergeStatus == "succeeded" {
+			mergeable = "true"
+		}
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            pr.Title,
+		Number:           pr.PullRequestID,
+		SCMProviderType:  "azure",
+		SCMProviderName:  a.providerName,
+		URL:              prURL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         mapKeys(approvedMap),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: mapKeys(changesRequestedMap),
+		PendingReviewers: pendingReviewers,
+		MyReview:         myReview,
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
@@ -0,0 +1,250 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/bitbucket"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type BitbucketCloudPRClient struct {
+	httpClient   *http.Client
+	apiURL       string
+	user         *types.User
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*BitbucketCloudPRClient)(nil)
+
+func NewBitbucketCloudPRClient(httpClient *http.Client, host string, user *types.User, providerName string, concurrency int) (*BitbucketCloudPRClient, error) {
+	return &BitbucketCloudPRClient{
+		httpClient:   httpClient,
+		apiURL:       bitbucket.CloudAPIURL(host),
+		user:         user,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (b *BitbucketCloudPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+
+	// NOTE: Bitbucket Cloud only lists the PRs of a user across repositories by author.
+	if role != RoleAuthor {
+		return prResponses, unsupportedRoleError("bitbucket-cloud", role)
+	}
+
+	prs, err := b.getPRs(ctx, state)
+	if err != nil {
+		return prResponses, err
+	}
+
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, len(prs))
+	respCh := make(chan *types.PullRequestResponse, len(prs))
+
+	// NOTE: The PRs are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(b.concurrency)
+	go func() {
+		for _, pr := range prs {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+
+			go func(pr *types.BitbucketCloudPullRequest) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prResponse, err := b.getPRDetails(ctx, pr, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prResponse
+			}(pr)
+		}
+		wg.Wait()
+		close(respCh)
+		close(errCh)
+	}()
+
+	var errs []error
+
+	for respCh != nil || errCh != nil {
+		select {
+		case resp, ok := <-respCh:
+			if !ok {
+				respCh = nil
+			} else {
+				prMutex.Lock()
+				prResponses = append(prResponses, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return prResponses, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return prResponses, nil
+}
+
+func (b *BitbucketCloudPRClient) getPRs(ctx context.Context, state string) ([]*types.BitbucketCloudPullRequest, error) {
+	var bitbucketStates = ""
+	if state == "open" {
+		bitbucketStates = "&state=OPEN"
+	} else if state == "merged" {
+		bitbucketStates = "&state=MERGED"
+	} else if state == "closed" {
+		bitbucketStates = "&state=DECLINED&state=SUPERSEDED"
+	} else if state == "all" {
+		bitbucketStates = "&state=OPEN&state=MERGED&state=DECLINED&state=SUPERSEDED"
+	}
+
+	var prs = make([]*types.BitbucketCloudPullRequest, 0)
+	apiURL := fmt.Sprintf("%s%s%s%s%s", b.apiURL, "/pullrequests/", url.PathEscape(b.user.AccountID),
+		"?pagelen=50", bitbucketStates)
+	for apiURL != "" {
+		page := &types.BitbucketCloudPullRequestPage{}
+		err := bitbucket.Get(ctx, b.httpClient, b.user.PAT, apiURL, page)
+		if err != nil {
+			return prs, fmt.Errorf("error fetching bitbucket cloud PRs for user %s: %w", b.user.Name, err)
+		}
+		prs = append(prs, page.Values...)
+		apiURL = page.Next
+	}
+	return prs, nil
+}
+
+func (b *BitbucketCloudPRClient) getPRDetails(
+	ctx context.Context,
+	pr *types.BitbucketCloudPullRequest,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	repoURL := fmt.Sprintf("%s%s%s%s%d", b.apiURL, "/repositories/", pr.Destination.Repository.FullName,
+		"/pullrequests/", pr.ID)
+
+	// NOTE: The cross-repository listing does not embed participants, so they are read from the PR itself.
+	details := &types.BitbucketCloudPullRequest{}
+	err := bitbucket.Get(ctx, b.httpClient, b.user.PAT, repoURL, details)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching PR details for %s: %w", pr.Links.HTML.Href, err)
+	}
+
+	approvedMap := map[string]bool{}
+	commentedMap := map[string]bool{}
+	changesRequestedMap := map[string]bool{}
+	pendingReviewers := make([]string, 0)
+	myReview := ""
+
+	// NOTE: The participants hold the current state of each reviewer, Bitbucket withdraws approvals on new commits when
+	// the repo asks for it.
+	for _, participant := range details.Participants {
+		userName := participant.User.DisplayName
+		approved := participant.Approved || (participant.State != nil && *participant.State == "approved")
+		changesRequested := !approved && participant.State != nil && *participant.State == "changes_requested"
+		commented := !approved && !changesRequested && participant.ParticipatedOn != nil
+		if approved {
+			approvedMap[userName] = true
+		} else if changesRequested {
+			changesRequestedMap[userName] = true
+		} else if commented {
+			commentedMap[userName] = true
+		}
+		if participant.Role == "REVIEWER" && !approved && !changesRequested {
+			pendingReviewers = append(pendingReviewers, userName)
+		}
+		if participant.User.AccountID == b.user.AccountID {
+			myReview = getMyReview(approved, changesRequested, commented, participant.Role == "REVIEWER")
+		}
+	}
+
+	state := "closed"
+	if pr.State == "OPEN" {
+		state = "open"
+	} else if pr.State == "MERGED" {
+		state = "merged"
+	}
+
+	// NOTE: Bitbucket Cloud does not expose the outcome of its merge checks over the API, so a PR is only known to be
+	// unmergeable when it has conflicts or change requests, and its mergeability is unknown otherwise.
+	mergeable := "-"
+	if state == "open" {
+		conflicted, err := b.hasConflicts(ctx, repoURL)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching PR merge details for %s: %w", pr.Links.HTML.Href, err)
+		}
+		if conflicted || len(changesRequestedMap) > 0 {
+			mergeable = "false"
+		}
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            pr.Title,
+		Number:           pr.ID,
+		SCMProviderType:  "bitbucket-cloud",
+		SCMProviderName:  b.providerName,
+		URL:              pr.Links.HTML.Href,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         mapKeys(approvedMap),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: mapKeys(changesRequestedMap),
+		PendingReviewers: pendingReviewers,
+		MyReview:         myReview,
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
+
+// hasConflicts reports whether the diff of the PR contains merge conflicts.
+func (b *BitbucketCloudPRClient) hasConflicts(ctx context.Context, repoURL string) (bool, error) {
+	apiURL := fmt.Sprintf("%s%s", repoURL, "/diffstat?pagelen=500")
+	for apiURL != "" {
+		page := &types.BitbucketCloudDiffStatPage{}
+		err := bitbucket.Get(ctx, b.httpClient, b.user.PAT, apiURL, page)
+		if err != nil {
+			return false, err
+		}
+		for _, diffStat := range page.Values {
+			if diffStat.Status == "merge conflict" {
+				return true, nil
+			}
+		}
+		apiURL = page.Next
+	}
+	return false, nil
+}
@@ -0,0 +1,238 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/bitbucket"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"sync"
+)
+
+type BitbucketDCPRClient struct {
+	httpClient   *http.Client
+	apiURL       string
+	user         *types.User
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*BitbucketDCPRClient)(nil)
+
+func NewBitbucketDCPRClient(httpClient *http.Client, host string, user *types.User, providerName string, concurrency int) (*BitbucketDCPRClient, error) {
+	return &BitbucketDCPRClient{
+		httpClient:   httpClient,
+		apiURL:       bitbucket.DataCenterAPIURL(host),
+		user:         user,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (b *BitbucketDCPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+
+	prs, err := b.getPRs(ctx, state, role)
+	if err != nil {
+		return prResponses, err
+	}
+
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, len(prs))
+	respCh := make(chan *types.PullRequestResponse, len(prs))
+
+	// NOTE: The PRs are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(b.concurrency)
+	go func() {
+		for _, pr := range prs {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+
+			go func(pr *types.BitbucketDCPullRequest) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prResponse, err := b.getPRDetails(ctx, pr, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prResponse
+			}(pr)
+		}
+		wg.Wait()
+		close(respCh)
+		close(errCh)
+	}()
+
+	var errs []error
+
+	for respCh != nil || errCh != nil {
+		select {
+		case resp, ok := <-respCh:
+			if !ok {
+				respCh = nil
+			} else {
+				prMutex.Lock()
+				prResponses = append(prResponses, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return prResponses, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return prResponses, nil
+}
+
+func (b *BitbucketDCPRClient) getPRs(
+	ctx context.Context,
+	state string,
+	role string,
+) ([]*types.BitbucketDCPullRequest, error) {
+	// NOTE: The dashboard lists the PRs of every role of the user when none is given.
+	var bitbucketRole = ""
+	switch role {
+	case RoleAuthor:
+		bitbucketRole = "&role=AUTHOR"
+	case RoleReviewer:
+		bitbucketRole = "&role=REVIEWER"
+	case RoleInvolved:
+		bitbucketRole = ""
+	default:
+		return nil, unsupportedRoleError("bitbucket-dc", role)
+	}
+
+	var bitbucketState = ""
+	if state == "open" {
+		bitbucketState = "&state=OPEN"
+	} else if state == "merged" {
+		bitbucketState = "&state=MERGED"
+	} else if state == "closed" {
+		bitbucketState = "&state=DECLINED"
+	}
+
+	var prs = make([]*types.BitbucketDCPullRequest, 0)
+	for start, isLastPage := 0, false; !isLastPage; {
+		apiURL := fmt.Sprintf("%s%s%s%s%s%d", b.apiURL, "/dashboard/pull-requests?order=NEWEST&limit=100",
+			bitbucketRole, bitbucketState, "&start=", start)
+		page := &types.BitbucketDCPullRequestPage{}
+		err := bitbucket.Get(ctx, b.httpClient, b.user.PAT, apiURL, page)
+		if err != nil {
+			return prs, fmt.Errorf("error fetching bitbucket data center PRs for user %s: %w", b.user.Name, err)
+		}
+		prs = append(prs, page.Values...)
+		start, isLastPage = page.NextPageStart, page.IsLastPage || len(page.Values) == 0
+	}
+	return prs, nil
+}
+
+func (b *BitbucketDCPRClient) getPRDetails(
+	ctx context.Context,
+	pr *types.BitbucketDCPullRequest,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	prURL := ""
+	if len(pr.Links.Self) > 0 {
+		prURL = pr.Links.Self[0].Href
+	}
+
+	commentedMap := map[string]bool{}
+	decisions := reviewDecisions{}
+	pendingReviewers := make([]string, 0)
+	myReview := ""
+
+	for _, participants := range [][]*types.BitbucketDCParticipant{pr.Reviewers, pr.Participants} {
+		for _, participant := range participants {
+			userName := participant.User.DisplayName
+			// NOTE: An approval of an outdated commit no longer vouches for the PR, the reviewer has to look again.
+			approved := participant.Status == "APPROVED" &&
+				(participant.LastReviewedCommit == "" || participant.LastReviewedCommit == pr.FromRef.LatestCommit)
+			changesRequested := participant.Status == "NEEDS_WORK"
+			// NOTE: Users only become participants (rather than reviewers) by commenting on the PR.
+			commented := !approved && !changesRequested && participant.Role == "PARTICIPANT"
+			if approved {
+				decisions.approve(userName)
+			} else if changesRequested {
+				decisions.requestChanges(userName)
+			} else if commented {
+				commentedMap[userName] = true
+			} else if participant.Role == "REVIEWER" {
+				pendingReviewers = append(pendingReviewers, userName)
+			}
+			if participant.User.ID == b.user.PrincipalID {
+				myReview = getMyReview(approved, changesRequested, commented, participant.Role == "REVIEWER")
+			}
+		}
+	}
+
+	state := "closed"
+	if pr.State == "OPEN" {
+		state = "open"
+	} else if pr.State == "MERGED" {
+		state = "merged"
+	}
+
+	mergeable := "-"
+	if state == "open" {
+		mergeStatus := &types.BitbucketDCMergeStatus{}
+		apiURL := fmt.Sprintf("%s%s%s%s%s%s%d%s", b.apiURL, "/projects/", pr.ToRef.Repository.Project.Key, "/repos/",
+			pr.ToRef.Repository.Slug, "/pull-requests/", pr.ID, "/merge")
+		err := bitbucket.Get(ctx, b.httpClient, b.user.PAT, apiURL, mergeStatus)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching PR merge details for %s: %w", prURL, err)
+		}
+		mergeable = "false"
+		if mergeStatus.CanMerge && !mergeStatus.Conflicted {
+			mergeable = "true"
+		}
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            pr.Title,
+		Number:           pr.ID,
+		SCMProviderType:  "bitbucket-dc",
+		SCMProviderName:  b.providerName,
+		URL:              prURL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         decisions.approved(),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: decisions.changesRequested(),
+		PendingReviewers: pendingReviewers,
+		MyReview:         myReview,
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
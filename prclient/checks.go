@@ -0,0 +1,69 @@
+package prclient
+
+import (
+	"github.com/dhruv1397/prm/types"
+	"strings"
+)
+
+type checkOutcome int
+
+const (
+	checkPassing checkOutcome = iota
+	checkFailing
+	checkPending
+)
+
+func newChecksSummary() *types.ChecksSummary {
+	return &types.ChecksSummary{FailingChecks: make([]string, 0)}
+}
+
+func addCheck(summary *types.ChecksSummary, name string, outcome checkOutcome) {
+	switch outcome {
+	case checkPassing:
+		summary.Passing++
+	case checkFailing:
+		summary.Failing++
+		summary.FailingChecks = append(summary.FailingChecks, name)
+	default:
+		summary.Pending++
+	}
+}
+
+// getGithubCheckRunOutcome maps the status and conclusion of a check run, as returned by either the REST or the
+// GraphQL API, to its outcome. See https://docs.github.com/en/rest/checks/runs for possible values.
+func getGithubCheckRunOutcome(status string, conclusion string) checkOutcome {
+	if !strings.EqualFold(status, "completed") {
+		return checkPending
+	}
+	switch strings.ToLower(conclusion) {
+	case "success", "neutral", "skipped":
+		return checkPassing
+	default:
+		return checkFailing
+	}
+}
+
+// getGithubStatusOutcome maps the state of a commit status, as returned by either the REST or the GraphQL API, to
+// its outcome.
+func getGithubStatusOutcome(state string) checkOutcome {
+	switch strings.ToLower(state) {
+	case "success":
+		return checkPassing
+	case "failure", "error":
+		return checkFailing
+	default:
+		return checkPending
+	}
+}
+
+// getHarnessCheckOutcome maps the status of a Harness status check to its outcome.
+func getHarnessCheckOutcome(status string) checkOutcome {
+	switch status {
+	case "success", "skipped", "failure_ignored":
+		return checkPassing
+	case "failure", "error":
+		return checkFailing
+	default:
+		return checkPending
+	}
+}
@@ -2,13 +2,70 @@ package prclient
 
 import (
 	"context"
+	"fmt"
 	"github.com/dhruv1397/prm/types"
 )
 
+// Roles of the user in the PRs to list.
+const (
+	RoleAuthor   = "author"
+	RoleReviewer = "reviewer"
+	RoleAssignee = "assignee"
+	// RoleInvolved covers every role the provider supports, eg the PRs the user authored, reviews or commented on.
+	RoleInvolved = "involved"
+)
+
+// Review states of the user on a PR, see types.PullRequest.MyReview.
+const (
+	myReviewApproved         = "approved"
+	myReviewRequestedChanges = "requested_changes"
+	myReviewCommented        = "commented"
+	myReviewPending          = "pending"
+)
+
+var verbose bool
+
+// SetVerbose makes the clients report what they fetched, eg how many of the PRs found by a search were fetched.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
 type PRClient interface {
+	// GetPullRequests lists the PRs in the state in which the user has the role.
 	GetPullRequests(
 		ctx context.Context,
 		state string,
+		role string,
 		transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
 	) ([]*types.PullRequestResponse, error)
 }
+
+func mapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// getMyReview sums up the review of the user on a PR. A requested review counts as pending until the user has approved
+// or requested changes, comments alone do not settle it.
+func getMyReview(approved bool, requestedChanges bool, commented bool, requested bool) string {
+	if requestedChanges {
+		return myReviewRequestedChanges
+	}
+	if approved {
+		return myReviewApproved
+	}
+	if requested {
+		return myReviewPending
+	}
+	if commented {
+		return myReviewCommented
+	}
+	return ""
+}
+
+func unsupportedRoleError(providerType string, role string) error {
+	return fmt.Errorf("role %s is not supported by %s", role, providerType)
+}
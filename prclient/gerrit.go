@@ -0,0 +1,280 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gerrit"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	gerritPageLimit              = 100
+	gerritCodeReview             = "Code-Review"
+	gerritAutogeneratedTagPrefix = "autogenerated:"
+	gerritServiceUserTag         = "SERVICE_USER"
+)
+
+type GerritPRClient struct {
+	httpClient   *http.Client
+	host         string
+	user         *types.User
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*GerritPRClient)(nil)
+
+func NewGerritPRClient(httpClient *http.Client, host string, user *types.User, providerName string, concurrency int) (*GerritPRClient, error) {
+	return &GerritPRClient{
+		httpClient:   httpClient,
+		host:         host,
+		user:         user,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (g *GerritPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+
+	changes, err := g.getChanges(ctx, state, role)
+	if err != nil {
+		return prResponses, err
+	}
+
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, len(changes))
+	respCh := make(chan *types.PullRequestResponse, len(changes))
+
+	// NOTE: The changes are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(g.concurrency)
+	go func() {
+		for _, change := range changes {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+
+			go func(change *types.GerritChange) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prResponse, err := g.getChangeDetails(ctx, change, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prResponse
+			}(change)
+		}
+		wg.Wait()
+		close(respCh)
+		close(errCh)
+	}()
+
+	var errs []error
+
+	for respCh != nil || errCh != nil {
+		select {
+		case resp, ok := <-respCh:
+			if !ok {
+				respCh = nil
+			} else {
+				prMutex.Lock()
+				prResponses = append(prResponses, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return prResponses, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return prResponses, nil
+}
+
+func (g *GerritPRClient) getChanges(ctx context.Context, state string, role string) ([]*types.GerritChange, error) {
+	// NOTE: Assignees were removed from Gerrit, and CCed users are only involved rather than reviewers.
+	var query string
+	switch role {
+	case RoleAuthor:
+		query = "owner:self"
+	case RoleReviewer:
+		query = "reviewer:self"
+	case RoleInvolved:
+		query = "(owner:self OR reviewer:self OR cc:self)"
+	default:
+		return nil, unsupportedRoleError("gerrit", role)
+	}
+	if state == "open" {
+		query += " status:open"
+	} else if state == "merged" {
+		query += " status:merged"
+	} else if state == "closed" {
+		query += " status:abandoned"
+	}
+
+	var changes = make([]*types.GerritChange, 0)
+	for start, moreChanges := 0, true; moreChanges; start += gerritPageLimit {
+		apiURL := fmt.Sprintf("%s/a/changes/?q=%s&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS&o=SUBMITTABLE&o=MESSAGES&n=%d&S=%d",
+			g.host, url.QueryEscape(query), gerritPageLimit, start)
+		pageChanges := make([]*types.GerritChange, 0)
+		err := gerrit.Get(ctx, g.httpClient, g.user.PAT, apiURL, &pageChanges)
+		if err != nil {
+			return changes, fmt.Errorf("error fetching gerrit changes for user %s: %w", g.user.Name, err)
+		}
+		changes = append(changes, pageChanges...)
+		// NOTE: Gerrit flags the last change of a page when more results are available.
+		moreChanges = len(pageChanges) > 0 && pageChanges[len(pageChanges)-1].MoreChanges
+	}
+	return changes, nil
+}
+
+func (g *GerritPRClient) getChangeDetails(
+	ctx context.Context,
+	change *types.GerritChange,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	changeURL := fmt.Sprintf("%s/c/%s/+/%d", g.host, change.Project, change.Number)
+
+	comments := map[string][]*types.GerritComment{}
+	apiURL := fmt.Sprintf("%s/a/changes/%s~%d/comments", g.host, url.PathEscape(change.Project), change.Number)
+	err := gerrit.Get(ctx, g.httpClient, g.user.PAT, apiURL, &comments)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching change comments for %s: %w", changeURL, err)
+	}
+
+	approvedMap := map[string]bool{}
+	commentedMap := map[string]bool{}
+	changesRequestedMap := map[string]bool{}
+	pendingReviewers := make([]string, 0)
+	var myVote *int
+	commentedByMe := false
+
+	// NOTE: The votes are those on the current patch set, Gerrit only carries them over from earlier patch sets as far as
+	// the copy conditions of the label allow.
+	if codeReview := change.Labels[gerritCodeReview]; codeReview != nil {
+		for _, approval := range codeReview.All {
+			// NOTE: Every reviewer is listed, with a zero vote until they have voted.
+			if approval.AccountID == g.user.PrincipalID {
+				myVote = &approval.Value
+			}
+			if approval.Value > 0 {
+				approvedMap[gerritAccountName(&approval.GerritAccount)] = true
+			} else if approval.Value < 0 {
+				changesRequestedMap[gerritAccountName(&approval.GerritAccount)] = true
+			} else {
+				pendingReviewers = append(pendingReviewers, gerritAccountName(&approval.GerritAccount))
+			}
+		}
+	}
+
+	for _, fileComments := range comments {
+		for _, comment := range fileComments {
+			commentedMap[gerritAccountName(&comment.Author)] = true
+			if comment.Author.AccountID == g.user.PrincipalID {
+				commentedByMe = true
+			}
+		}
+	}
+
+	// NOTE: Top-level replies are change messages rather than comments. Messages of the owner and of automated accounts,
+	// eg patch set uploads and CI results, and replies which only vote are not reviews.
+	for _, message := range change.Messages {
+		if message.Author == nil || message.Author.AccountID == change.Owner.AccountID ||
+			strings.HasPrefix(message.Tag, gerritAutogeneratedTagPrefix) ||
+			slices.Contains(message.Author.Tags, gerritServiceUserTag) || !hasGerritReplyText(message.Message) {
+			continue
+		}
+		commentedMap[gerritAccountName(message.Author)] = true
+		if message.Author.AccountID == g.user.PrincipalID {
+			commentedByMe = true
+		}
+	}
+
+	myReview := ""
+	if myVote != nil {
+		myReview = getMyReview(*myVote > 0, *myVote < 0, commentedByMe, true)
+	} else if commentedByMe {
+		myReview = myReviewCommented
+	}
+
+	state := "open"
+	if change.Status == "MERGED" {
+		state = "merged"
+	} else if change.Status == "ABANDONED" {
+		state = "closed"
+	}
+
+	mergeable := "-"
+	if state == "open" {
+		mergeable = strconv.FormatBool(change.Submittable)
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            change.Subject,
+		Number:           change.Number,
+		SCMProviderType:  "gerrit",
+		SCMProviderName:  g.providerName,
+		URL:              changeURL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         mapKeys(approvedMap),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: mapKeys(changesRequestedMap),
+		PendingReviewers: pendingReviewers,
+		MyReview:         myReview,
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
+
+func gerritAccountName(account *types.GerritAccount) string {
+	if account.Username != "" {
+		return account.Username
+	}
+	return account.Name
+}
+
+// hasGerritReplyText reports whether the change message holds more than the "Patch Set N: Code-Review+1" header which
+// Gerrit puts in front of every reply.
+func hasGerritReplyText(message string) bool {
+	if strings.HasPrefix(message, "Patch Set ") {
+		_, message, _ = strings.Cut(message, "\n")
+	}
+	return strings.TrimSpace(message) != ""
+}
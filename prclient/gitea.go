@@ -0,0 +1,290 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gitea"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+const giteaPageLimit = 50
+
+type GiteaPRClient struct {
+	httpClient   *http.Client
+	host         string
+	user         *types.User
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*GiteaPRClient)(nil)
+
+func NewGiteaPRClient(httpClient *http.Client, host string, user *types.User, providerName string, concurrency int) (*GiteaPRClient, error) {
+	return &GiteaPRClient{
+		httpClient:   httpClient,
+		host:         host,
+		user:         user,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (g *GiteaPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+
+	issues, err := g.searchPRs(ctx, state, role)
+	if err != nil {
+		return prResponses, err
+	}
+
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, len(issues))
+	respCh := make(chan *types.PullRequestResponse, len(issues))
+
+	// NOTE: The PRs are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(g.concurrency)
+	go func() {
+		for _, issue := range issues {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+
+			go func(issue *types.GiteaIssue) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prResponse, err := g.getPRDetails(ctx, issue, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prResponse
+			}(issue)
+		}
+		wg.Wait()
+		close(respCh)
+		close(errCh)
+	}()
+
+	var errs []error
+
+	for respCh != nil || errCh != nil {
+		select {
+		case resp, ok := <-respCh:
+			if !ok {
+				respCh = nil
+			} else {
+				prMutex.Lock()
+				prResponses = append(prResponses, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return prResponses, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return prResponses, nil
+}
+
+func (g *GiteaPRClient) searchPRs(ctx context.Context, state string, role string) ([]*types.GiteaIssue, error) {
+	// NOTE: The issue search API has no notion of merged PRs, so merged and closed are both fetched as closed and
+	// told apart afterwards.
+	var giteaState = "all"
+	if state == "open" {
+		giteaState = "open"
+	} else if state == "merged" || state == "closed" {
+		giteaState = "closed"
+	}
+
+	// NOTE: The search ANDs its user filters, so the PRs the user is involved in are searched for one filter at a
+	// time.
+	var userFilters []string
+	switch role {
+	case RoleAuthor:
+		userFilters = []string{"created"}
+	case RoleReviewer:
+		// NOTE: Gitea drops the review request once the user approves or requests changes, so the PRs they reviewed
+		// are searched too.
+		userFilters = []string{"review_requested", "reviewed"}
+	case RoleAssignee:
+		userFilters = []string{"assigned"}
+	case RoleInvolved:
+		userFilters = []string{"created", "review_requested", "reviewed", "assigned", "mentioned"}
+	default:
+		return nil, unsupportedRoleError("gitea", role)
+	}
+
+	var issues = make([]*types.GiteaIssue, 0)
+	seen := map[string]bool{}
+	for _, userFilter := range userFilters {
+		for page := 1; ; page++ {
+			apiURL := fmt.Sprintf("%s/api/v1/repos/issues/search?type=pulls&%s=true&state=%s&limit=%d&page=%d",
+				g.host, userFilter, giteaState, giteaPageLimit, page)
+			pageIssues := make([]*types.GiteaIssue, 0)
+			err := gitea.Get(ctx, g.httpClient, g.user.PAT, apiURL, &pageIssues)
+			if err != nil {
+				return issues, fmt.Errorf("error fetching gitea PRs for user %s: %w", g.user.Name, err)
+			}
+			for _, issue := range pageIssues {
+				merged := issue.PullRequest != nil && issue.PullRequest.Merged
+				if (state == "merged" && !merged) || (state == "closed" && merged) || seen[issue.HTMLURL] {
+					continue
+				}
+				seen[issue.HTMLURL] = true
+				issues = append(issues, issue)
+			}
+			if len(pageIssues) < giteaPageLimit {
+				break
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (g *GiteaPRClient) getPRDetails(
+	ctx context.Context,
+	issue *types.GiteaIssue,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	repoURL := fmt.Sprintf("%s/api/v1/repos/%s/%s", g.host, url.PathEscape(issue.Repository.Owner),
+		url.PathEscape(issue.Repository.Name))
+
+	pr := &types.GiteaPullRequest{}
+	err := gitea.Get(ctx, g.httpClient, g.user.PAT, fmt.Sprintf("%s/pulls/%d", repoURL, issue.Number), pr)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching PR details for %s: %w", issue.HTMLURL, err)
+	}
+
+	reviews, err := g.getReviews(ctx, repoURL, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	commentedMap := map[string]bool{}
+	decisions := reviewDecisions{}
+	pendingMap := map[string]bool{}
+	pendingReviewers := make([]string, 0)
+
+	for _, review := range reviews {
+		userName := review.User.Login
+		if review.Team != nil {
+			userName = review.Team.Name
+		}
+		if review.Dismissed {
+			decisions.dismiss(userName)
+			continue
+		}
+		switch review.State {
+		// NOTE: Review requests are listed as reviews too, the reviewer stays pending until they approve or request
+		// changes.
+		case "REQUEST_REVIEW":
+			if !slices.Contains(pendingReviewers, userName) {
+				pendingReviewers = append(pendingReviewers, userName)
+			}
+			pendingMap[userName] = true
+		case "APPROVED":
+			// NOTE: Gitea marks the reviews of outdated commits as stale, such approvals no longer vouch for the PR and
+			// the reviewer has to review it again.
+			if review.Stale {
+				decisions.dismiss(userName)
+				if !slices.Contains(pendingReviewers, userName) {
+					pendingReviewers = append(pendingReviewers, userName)
+				}
+			} else {
+				decisions.approve(userName)
+			}
+			pendingMap[userName] = review.Stale
+		case "REQUEST_CHANGES":
+			decisions.requestChanges(userName)
+			pendingMap[userName] = false
+		case "COMMENT":
+			commentedMap[userName] = true
+		}
+	}
+	pendingReviewers = slices.DeleteFunc(pendingReviewers, func(reviewer string) bool {
+		return !pendingMap[reviewer]
+	})
+
+	state := pr.State
+	mergeable := strconv.FormatBool(pr.Mergeable)
+	if pr.Merged {
+		state = "merged"
+		mergeable = "-"
+	} else if state == "closed" {
+		mergeable = "-"
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            pr.Title,
+		Number:           pr.Number,
+		SCMProviderType:  "gitea",
+		SCMProviderName:  g.providerName,
+		URL:              issue.HTMLURL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         decisions.approved(),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: decisions.changesRequested(),
+		PendingReviewers: pendingReviewers,
+		MyReview: getMyReview(decisions.hasApproved(g.user.Name), decisions.hasRequestedChanges(g.user.Name),
+			commentedMap[g.user.Name], pendingMap[g.user.Name]),
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
+
+// getReviews lists all the reviews of the PR, oldest first.
+func (g *GiteaPRClient) getReviews(
+	ctx context.Context,
+	repoURL string,
+	issue *types.GiteaIssue,
+) ([]*types.GiteaReview, error) {
+	var reviews = make([]*types.GiteaReview, 0)
+	for page := 1; ; page++ {
+		apiURL := fmt.Sprintf("%s/pulls/%d/reviews?limit=%d&page=%d", repoURL, issue.Number, giteaPageLimit, page)
+		pageReviews := make([]*types.GiteaReview, 0)
+		err := gitea.Get(ctx, g.httpClient, g.user.PAT, apiURL, &pageReviews)
+		if err != nil {
+			return reviews, fmt.Errorf("error fetching PR reviews for %s: %w", issue.HTMLURL, err)
+		}
+		reviews = append(reviews, pageReviews...)
+		if len(pageReviews) < giteaPageLimit {
+			return reviews, nil
+		}
+	}
+}
@@ -3,33 +3,49 @@ package prclient
 import (
 	"context"
 	"fmt"
+	"github.com/dhruv1397/prm/transport"
 	"github.com/dhruv1397/prm/types"
 	"github.com/dhruv1397/prm/util"
 	"github.com/google/go-github/v64/github"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
+const (
+	githubSearchPageSize    = 100
+	githubSearchResultLimit = 1000
+	githubSearchTimeLayout  = "2006-01-02T15:04:05-07:00"
+)
+
+// githubSearchEpoch predates every PR on GitHub and bounds the creation date ranges used to split large searches.
+var githubSearchEpoch = time.Date(2008, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 type GithubPRClient struct {
 	user         *types.User
 	client       *github.Client
 	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
 }
 
 var _ PRClient = (*GithubPRClient)(nil)
 
-func NewGithubPRClient(user *types.User, client *github.Client, providerName string) (*GithubPRClient, error) {
+func NewGithubPRClient(user *types.User, client *github.Client, providerName string, concurrency int) (*GithubPRClient, error) {
 	return &GithubPRClient{
 		user:         user,
 		client:       client,
 		providerName: providerName,
+		concurrency:  concurrency,
 	}, nil
 }
 
 func (g *GithubPRClient) GetPullRequests(
 	ctx context.Context,
 	state string,
+	role string,
 	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
 ) ([]*types.PullRequestResponse, error) {
 	var prResponses = make([]*types.PullRequestResponse, 0)
@@ -44,43 +60,45 @@ func (g *GithubPRClient) GetPullRequests(
 		githubState = ""
 	}
 
-	query := fmt.Sprintf("%s author:%s type:pr", githubState, g.user.Name)
-
-	opts := &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 500},
-		Order:       "desc",
-	}
-
-	result, _, err := g.client.Search.Issues(ctx, query, opts)
+	issues, err := searchGithubPRsWithRole(githubState, role, g.user.Name, (*github.Issue).GetHTMLURL,
+		func(query string) ([]*github.Issue, int, error) {
+			return g.searchPRsCreatedBetween(ctx, query, githubSearchEpoch, time.Now().UTC())
+		})
 	if err != nil {
-		return prResponses, fmt.Errorf("error fetching github PRs for user %s: %w", g.user.Name, err)
+		return prResponses, err
 	}
 
 	var prMutex sync.Mutex
 	var errMutex sync.Mutex
 	var wg sync.WaitGroup
 
-	errCh := make(chan error, 500)
-	respCh := make(chan *types.PullRequestResponse, 500)
+	errCh := make(chan error)
+	respCh := make(chan *types.PullRequestResponse)
 
-	for _, issue := range result.Issues {
-		wg.Add(1)
-
-		go func(issue *github.Issue) {
-			defer wg.Done()
-
-			prResponse, err := g.getPRDetails(ctx, issue, transformationFn)
-			if err != nil {
+	// NOTE: The PRs are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(g.concurrency)
+	go func() {
+		for _, issue := range issues {
+			if err := limiter.Acquire(ctx); err != nil {
 				errCh <- err
-				return
+				break
 			}
+			wg.Add(1)
 
-			respCh <- prResponse
-		}(issue)
+			go func(issue *github.Issue) {
+				defer wg.Done()
+				defer limiter.Release()
 
-	}
+				prResponse, err := g.getPRDetails(ctx, issue, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
 
-	go func() {
+				respCh <- prResponse
+			}(issue)
+		}
 		wg.Wait()
 		close(respCh)
 		close(errCh)
@@ -116,6 +134,85 @@ func (g *GithubPRClient) GetPullRequests(
 	return prResponses, nil
 }
 
+// searchPRsCreatedBetween returns the search hits for PRs created in the given time range along with the total
+// number of hits reported by GitHub, see searchGithubPRsCreatedBetween.
+func (g *GithubPRClient) searchPRsCreatedBetween(
+	ctx context.Context,
+	query string,
+	from time.Time,
+	to time.Time,
+) ([]*github.Issue, int, error) {
+	return searchGithubPRsCreatedBetween(query, from, to,
+		func(rangeQuery string) (int, func() ([]*github.Issue, error), error) {
+			opts := &github.SearchOptions{
+				ListOptions: github.ListOptions{PerPage: githubSearchPageSize},
+				Sort:        "created",
+				Order:       "desc",
+			}
+			result, resp, err := g.client.Search.Issues(ctx, rangeQuery, opts)
+			if err != nil {
+				return 0, nil, err
+			}
+			fetchAll := func() ([]*github.Issue, error) {
+				issues := result.Issues
+				for resp.NextPage != 0 {
+					opts.Page = resp.NextPage
+					result, resp, err = g.client.Search.Issues(ctx, rangeQuery, opts)
+					if err != nil {
+						return nil, err
+					}
+					issues = append(issues, result.Issues...)
+				}
+				return issues, nil
+			}
+			return result.GetTotal(), fetchAll, nil
+		})
+}
+
+// searchGithubPRsCreatedBetween returns the search hits for PRs created in the given time range along with the total
+// number of hits reported by GitHub, for both the REST and the GraphQL API. The search serves at most 1000 results per
+// query, so ranges with more hits are split in half until each part fits. search runs the query for a range and returns
+// the number of hits along with a function fetching all of them, which is only called for the ranges which fit.
+func searchGithubPRsCreatedBetween[T any](
+	query string,
+	from time.Time,
+	to time.Time,
+	search func(rangeQuery string) (int, func() ([]T, error), error),
+) ([]T, int, error) {
+	rangeQuery := fmt.Sprintf("%s created:%s..%s", query, from.Format(githubSearchTimeLayout),
+		to.Format(githubSearchTimeLayout))
+
+	total, fetchAll, err := search(rangeQuery)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if total > githubSearchResultLimit && to.Sub(from) > time.Second {
+		mid := from.Add(to.Sub(from) / 2).Truncate(time.Second)
+		// NOTE: The bounds are inclusive and in whole seconds, the newer half starts a second after the older one ends
+		// but never after the end of the range.
+		newerFrom := mid.Add(time.Second)
+		if newerFrom.After(to) {
+			newerFrom = to
+		}
+		newer, newerTotal, err := searchGithubPRsCreatedBetween(query, newerFrom, to, search)
+		if err != nil {
+			return nil, 0, err
+		}
+		older, olderTotal, err := searchGithubPRsCreatedBetween(query, from, mid, search)
+		if err != nil {
+			return nil, 0, err
+		}
+		return append(newer, older...), newerTotal + olderTotal, nil
+	}
+
+	hits, err := fetchAll()
+	if err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
 func (g *GithubPRClient) getPRDetails(
 	ctx context.Context,
 	issue *github.Issue,
@@ -137,42 +234,55 @@ func (g *GithubPRClient) getPRDetails(
 		mergeable = "true"
 	}
 
-	reviews, _, err := g.client.PullRequests.ListReviews(ctx, owner, repo, *issue.Number, nil)
+	reviews, err := g.listReviews(ctx, owner, repo, *issue.Number)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching PR reviews for %s: %w", *issue.HTMLURL, err)
 	}
 
-	approvedMap := map[string]bool{}
 	commentedMap := map[string]bool{}
-	changesRequestedMap := map[string]bool{}
+	decisions := reviewDecisions{}
+	staleApprovers := map[string]bool{}
+	reviewRequested := false
+
+	// NOTE: GitHub drops reviewers from the requested ones once they review, until their review is requested again.
+	pendingReviewers := make([]string, 0, len(pr.RequestedReviewers)+len(pr.RequestedTeams))
+	for _, reviewer := range pr.RequestedReviewers {
+		if reviewer.GetLogin() == g.user.Name {
+			reviewRequested = true
+		}
+		pendingReviewers = append(pendingReviewers, reviewer.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		pendingReviewers = append(pendingReviewers, owner+"/"+team.GetSlug())
+	}
 
 	for _, review := range reviews {
 		userName := *(review.User.Login)
-		if review.GetState() == "APPROVED" {
-			approvedMap[userName] = true
-		}
-		if review.GetState() == "COMMENTED" {
+		switch review.GetState() {
+		case "APPROVED":
+			// NOTE: An approval of an outdated commit no longer vouches for the PR, whether or not the repo dismisses
+			// stale approvals.
+			if review.GetCommitID() == pr.GetHead().GetSHA() {
+				decisions.approve(userName)
+			} else {
+				decisions.dismiss(userName)
+			}
+			staleApprovers[userName] = !decisions.hasApproved(userName)
+		case "CHANGES_REQUESTED":
+			decisions.requestChanges(userName)
+			staleApprovers[userName] = false
+		case "DISMISSED":
+			decisions.dismiss(userName)
+			staleApprovers[userName] = false
+		case "COMMENTED":
 			commentedMap[userName] = true
 		}
-		if review.GetState() == "CHANGES_REQUESTED" {
-			changesRequestedMap[userName] = true
-		}
-	}
-
-	approved := make([]string, 0, len(approvedMap))
-	for k := range approvedMap {
-		approved = append(approved, k)
 	}
+	pendingReviewers = addStaleApprovers(pendingReviewers, staleApprovers)
 
-	commented := make([]string, 0, len(commentedMap))
-	for k := range commentedMap {
-		commented = append(commented, k)
-	}
-
-	changesRequested := make([]string, 0, len(changesRequestedMap))
-	for k := range changesRequestedMap {
-		changesRequested = append(changesRequested, k)
-	}
+	approved := decisions.approved()
+	commented := mapKeys(commentedMap)
+	changesRequested := decisions.changesRequested()
 
 	state := *pr.State
 	if *pr.Merged {
@@ -180,6 +290,19 @@ func (g *GithubPRClient) getPRDetails(
 		mergeable = "-"
 	}
 
+	var checks *types.ChecksSummary
+	if state == "open" {
+		checks, err = g.getChecks(ctx, owner, repo, pr.GetHead().GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("error fetching PR checks for %s: %w", *issue.HTMLURL, err)
+		}
+	}
+
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+
 	rawPR := &types.PullRequest{
 		Title:            *pr.Title,
 		Number:           *pr.Number,
@@ -191,6 +314,21 @@ func (g *GithubPRClient) getPRDetails(
 		Approved:         approved,
 		Commented:        commented,
 		RequestedChanges: changesRequested,
+		PendingReviewers: pendingReviewers,
+		MyReview: getMyReview(decisions.hasApproved(g.user.Name), decisions.hasRequestedChanges(g.user.Name),
+			commentedMap[g.user.Name], reviewRequested || staleApprovers[g.user.Name]),
+		Checks:       checks,
+		Draft:        pr.GetDraft(),
+		Labels:       labels,
+		Repo:         pr.GetBase().GetRepo().GetFullName(),
+		SourceBranch: pr.GetHead().GetRef(),
+		TargetBranch: pr.GetBase().GetRef(),
+		Author:       pr.GetUser().GetLogin(),
+		Created:      pr.GetCreatedAt().UnixMilli(),
+		Updated:      pr.GetUpdatedAt().UnixMilli(),
+	}
+	if pr.MergedAt != nil {
+		rawPR.Merged = pr.GetMergedAt().UnixMilli()
 	}
 
 	printablePR := transformationFn(rawPR)
@@ -201,6 +339,126 @@ func (g *GithubPRClient) getPRDetails(
 	}, nil
 }
 
+// listReviews lists all the reviews of the PR, oldest first.
+func (g *GithubPRClient) listReviews(
+	ctx context.Context,
+	owner string,
+	repo string,
+	number int,
+) ([]*github.PullRequestReview, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var reviews []*github.PullRequestReview
+	for {
+		page, resp, err := g.client.PullRequests.ListReviews(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, page...)
+		if resp.NextPage == 0 {
+			return reviews, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// getChecks sums up the check runs and commit statuses of the commit, the two ways CI reports to GitHub.
+func (g *GithubPRClient) getChecks(
+	ctx context.Context,
+	owner string,
+	repo string,
+	sha string,
+) (*types.ChecksSummary, error) {
+	checks := newChecksSummary()
+
+	checkRunOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: githubSearchPageSize}}
+	for {
+		result, resp, err := g.client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, checkRunOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, checkRun := range result.CheckRuns {
+			addCheck(checks, checkRun.GetName(),
+				getGithubCheckRunOutcome(checkRun.GetStatus(), checkRun.GetConclusion()))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		checkRunOpts.Page = resp.NextPage
+	}
+
+	statusOpts := &github.ListOptions{PerPage: githubSearchPageSize}
+	for {
+		combinedStatus, resp, err := g.client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, statusOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range combinedStatus.Statuses {
+			addCheck(checks, status.GetContext(), getGithubStatusOutcome(status.GetState()))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		statusOpts.Page = resp.NextPage
+	}
+
+	return checks, nil
+}
+
+// getGithubRoleQualifiers returns the search qualifiers matching the PRs in which the user has the role, the PRs
+// matching any of them are listed.
+func getGithubRoleQualifiers(role string) ([]string, error) {
+	switch role {
+	case RoleAuthor:
+		return []string{"author"}, nil
+	case RoleReviewer:
+		// NOTE: GitHub drops the review request once the user reviews, so the PRs they reviewed are searched too.
+		return []string{"review-requested", "reviewed-by"}, nil
+	case RoleAssignee:
+		return []string{"assignee"}, nil
+	case RoleInvolved:
+		return []string{"involves"}, nil
+	default:
+		return nil, unsupportedRoleError("github", role)
+	}
+}
+
+// searchGithubPRsWithRole runs a search per qualifier of the role, for both the REST and the GraphQL API, and merges
+// the hits, dropping those with the same URL.
+func searchGithubPRsWithRole[T any](
+	githubState string,
+	role string,
+	userName string,
+	getURL func(T) string,
+	search func(query string) ([]T, int, error),
+) ([]T, error) {
+	roleQualifiers, err := getGithubRoleQualifiers(role)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]T, 0)
+	seen := map[string]bool{}
+	for _, roleQualifier := range roleQualifiers {
+		query := fmt.Sprintf("%s %s:%s type:pr", githubState, roleQualifier, userName)
+		hits, total, err := search(query)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching github PRs for user %s: %w", userName, err)
+		}
+		// NOTE: The search API returns at most 1000 hits, so fewer PRs than found may be fetched.
+		if verbose || len(hits) < total {
+			fmt.Fprintf(os.Stderr, "Found %d github PRs with %s:%s, fetched %d\n", total, roleQualifier, userName,
+				len(hits))
+		}
+		for _, pr := range hits {
+			if !seen[getURL(pr)] {
+				seen[getURL(pr)] = true
+				prs = append(prs, pr)
+			}
+		}
+	}
+	return prs, nil
+}
+
 func parseGithubURL(githubURL string) (string, string, error) {
 	parsedURL, err := url.Parse(githubURL)
 	if err != nil {
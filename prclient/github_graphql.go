@@ -0,0 +1,356 @@
+package prclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"io"
+	"net/http"
+	"time"
+)
+
+// githubGraphQLPageSize is kept well below the search maximum of 100 since every hit also pulls its reviews, review
+// requests and check rollup, which count towards the query cost.
+const githubGraphQLPageSize = 50
+
+const githubGraphQLSearchQuery = `query($query: String!, $first: Int!, $after: String) {
+  search(query: $query, type: ISSUE, first: $first, after: $after) {
+    issueCount
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+    nodes {
+      ... on PullRequest {
+        number
+        title
+        url
+        state
+        merged
+        mergeable
+        mergeStateStatus
+        isDraft
+        headRefOid
+        headRefName
+        baseRefName
+        createdAt
+        updatedAt
+        mergedAt
+        repository {
+          nameWithOwner
+        }
+        author {
+          login
+        }
+        labels(first: 20) {
+          nodes {
+            name
+          }
+        }
+        reviews(last: 100) {
+          nodes {
+            state
+            author {
+              login
+            }
+            commit {
+              oid
+            }
+          }
+        }
+        reviewRequests(first: 50) {
+          nodes {
+            requestedReviewer {
+              ... on User {
+                login
+              }
+              ... on Bot {
+                login
+              }
+              ... on Mannequin {
+                login
+              }
+              ... on Team {
+                combinedSlug
+              }
+            }
+          }
+        }
+        commits(last: 1) {
+          nodes {
+            commit {
+              statusCheckRollup {
+                state
+                contexts(first: 100) {
+                  nodes {
+                    __typename
+                    ... on CheckRun {
+                      name
+                      status
+                      conclusion
+                    }
+                    ... on StatusContext {
+                      context
+                      state
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// GithubGraphQLPRClient fetches the PRs of a GitHub user through the GraphQL API, which returns the details of all
+// search hits with the search itself instead of requiring separate REST calls per PR.
+type GithubGraphQLPRClient struct {
+	user         *types.User
+	httpClient   *http.Client
+	graphQLURL   string
+	providerName string
+}
+
+var _ PRClient = (*GithubGraphQLPRClient)(nil)
+
+func NewGithubGraphQLPRClient(
+	user *types.User,
+	httpClient *http.Client,
+	graphQLURL string,
+	providerName string,
+) (*GithubGraphQLPRClient, error) {
+	return &GithubGraphQLPRClient{
+		user:         user,
+		httpClient:   httpClient,
+		graphQLURL:   graphQLURL,
+		providerName: providerName,
+	}, nil
+}
+
+func (g *GithubGraphQLPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+	var githubState = ""
+	if state == "closed" {
+		githubState = "state:closed is:unmerged"
+	} else if state == "merged" {
+		githubState = "state:closed is:merged"
+	} else if state == "open" {
+		githubState = "state:open"
+	}
+
+	prs, err := searchGithubPRsWithRole(githubState, role, g.user.Name,
+		func(pr *types.GithubGraphQLPullRequest) string { return pr.URL },
+		func(query string) ([]*types.GithubGraphQLPullRequest, int, error) {
+			return g.searchPRsCreatedBetween(ctx, query, githubSearchEpoch, time.Now().UTC())
+		})
+	if err != nil {
+		return prResponses, err
+	}
+
+	for _, pr := range prs {
+		rawPR := g.toPullRequest(pr)
+		prResponses = append(prResponses, &types.PullRequestResponse{
+			PR:          rawPR,
+			PrintablePR: transformationFn(rawPR),
+		})
+	}
+
+	return prResponses, nil
+}
+
+// searchPRsCreatedBetween is the GraphQL counterpart of GithubPRClient.searchPRsCreatedBetween, as the GraphQL search
+// is subject to the same 1000 result cap.
+func (g *GithubGraphQLPRClient) searchPRsCreatedBetween(
+	ctx context.Context,
+	query string,
+	from time.Time,
+	to time.Time,
+) ([]*types.GithubGraphQLPullRequest, int, error) {
+	return searchGithubPRsCreatedBetween(query, from, to,
+		func(rangeQuery string) (int, func() ([]*types.GithubGraphQLPullRequest, error), error) {
+			search, err := g.search(ctx, rangeQuery, nil)
+			if err != nil {
+				return 0, nil, err
+			}
+			fetchAll := func() ([]*types.GithubGraphQLPullRequest, error) {
+				prs := search.Nodes
+				for search.PageInfo.HasNextPage {
+					after := search.PageInfo.EndCursor
+					search, err = g.search(ctx, rangeQuery, &after)
+					if err != nil {
+						return nil, err
+					}
+					prs = append(prs, search.Nodes...)
+				}
+				return prs, nil
+			}
+			return search.IssueCount, fetchAll, nil
+		})
+}
+
+func (g *GithubGraphQLPRClient) search(
+	ctx context.Context,
+	query string,
+	after *string,
+) (*types.GithubGraphQLSearch, error) {
+	reqBody := types.GithubGraphQLRequest{
+		Query: githubGraphQLSearchQuery,
+		Variables: map[string]any{
+			"query": query,
+			"first": githubGraphQLPageSize,
+			"after": after,
+		},
+	}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling request body: %w", err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, g.graphQLURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error while forming request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	transport.MarkIdempotent(r)
+
+	response, err := g.httpClient.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response body: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected response status %s: %s", response.Status, string(body))
+	}
+
+	responseObj := &types.GithubGraphQLResponse{}
+	err = json.Unmarshal(body, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing response: %w", err)
+	}
+	if len(responseObj.Errors) > 0 {
+		return nil, fmt.Errorf("error in graphql response: %s", responseObj.Errors[0].Message)
+	}
+	return &responseObj.Data.Search, nil
+}
+
+func (g *GithubGraphQLPRClient) toPullRequest(pr *types.GithubGraphQLPullRequest) *types.PullRequest {
+	commentedMap := map[string]bool{}
+	decisions := reviewDecisions{}
+	staleApprovers := map[string]bool{}
+	reviewRequested := false
+
+	pendingReviewers := make([]string, 0, len(pr.ReviewRequests.Nodes))
+	for _, reviewRequest := range pr.ReviewRequests.Nodes {
+		if reviewRequest.RequestedReviewer.Login == g.user.Name {
+			reviewRequested = true
+		}
+		if reviewRequest.RequestedReviewer.CombinedSlug != "" {
+			pendingReviewers = append(pendingReviewers, reviewRequest.RequestedReviewer.CombinedSlug)
+		} else {
+			pendingReviewers = append(pendingReviewers, reviewRequest.RequestedReviewer.Login)
+		}
+	}
+
+	for _, review := range pr.Reviews.Nodes {
+		userName := review.Author.Login
+		switch review.State {
+		case "APPROVED":
+			// NOTE: See GithubPRClient.getPRDetails for why approvals of outdated commits are dropped.
+			if review.Commit.Oid == pr.HeadRefOid {
+				decisions.approve(userName)
+			} else {
+				decisions.dismiss(userName)
+			}
+			staleApprovers[userName] = !decisions.hasApproved(userName)
+		case "CHANGES_REQUESTED":
+			decisions.requestChanges(userName)
+			staleApprovers[userName] = false
+		case "DISMISSED":
+			decisions.dismiss(userName)
+			staleApprovers[userName] = false
+		case "COMMENTED":
+			commentedMap[userName] = true
+		}
+	}
+	pendingReviewers = addStaleApprovers(pendingReviewers, staleApprovers)
+
+	state := "open"
+	if pr.State == "CLOSED" {
+		state = "closed"
+	}
+
+	var mergeable = "false"
+	// NOTE: See https://docs.github.com/en/graphql/reference/enums#mergestatestatus for possible values.
+	if pr.Mergeable == "MERGEABLE" &&
+		(pr.MergeStateStatus == "CLEAN" || pr.MergeStateStatus == "UNSTABLE" || pr.MergeStateStatus == "HAS_HOOKS") {
+		mergeable = "true"
+	}
+	if pr.Merged {
+		state = "merged"
+		mergeable = "-"
+	}
+
+	var checks *types.ChecksSummary
+	if state == "open" && len(pr.Commits.Nodes) > 0 {
+		checks = newChecksSummary()
+		if rollup := pr.Commits.Nodes[0].Commit.StatusCheckRollup; rollup != nil {
+			for _, checkContext := range rollup.Contexts.Nodes {
+				if checkContext.TypeName == "CheckRun" {
+					addCheck(checks, checkContext.Name,
+						getGithubCheckRunOutcome(checkContext.Status, checkContext.Conclusion))
+				} else {
+					addCheck(checks, checkContext.Context, getGithubStatusOutcome(checkContext.State))
+				}
+			}
+		}
+	}
+
+	labels := make([]string, 0, len(pr.Labels.Nodes))
+	for _, label := range pr.Labels.Nodes {
+		labels = append(labels, label.Name)
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            pr.Title,
+		Number:           pr.Number,
+		SCMProviderType:  "github",
+		SCMProviderName:  g.providerName,
+		URL:              pr.URL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         decisions.approved(),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: decisions.changesRequested(),
+		PendingReviewers: pendingReviewers,
+		MyReview: getMyReview(decisions.hasApproved(g.user.Name), decisions.hasRequestedChanges(g.user.Name),
+			commentedMap[g.user.Name], reviewRequested || staleApprovers[g.user.Name]),
+		Checks:       checks,
+		Draft:        pr.IsDraft,
+		Labels:       labels,
+		Repo:         pr.Repository.NameWithOwner,
+		SourceBranch: pr.HeadRefName,
+		TargetBranch: pr.BaseRefName,
+		Author:       pr.Author.Login,
+		Created:      pr.CreatedAt.UnixMilli(),
+		Updated:      pr.UpdatedAt.UnixMilli(),
+	}
+	if pr.MergedAt != nil {
+		rawPR.Merged = pr.MergedAt.UnixMilli()
+	}
+
+	return rawPR
+}
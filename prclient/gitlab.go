@@ -0,0 +1,269 @@
+package prclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gitlab"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"sync"
+)
+
+type GitlabPRClient struct {
+	httpClient   *http.Client
+	host         string
+	user         *types.User
+	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+var _ PRClient = (*GitlabPRClient)(nil)
+
+func NewGitlabPRClient(httpClient *http.Client, host string, user *types.User, providerName string, concurrency int) (*GitlabPRClient, error) {
+	return &GitlabPRClient{
+		httpClient:   httpClient,
+		host:         host,
+		user:         user,
+		providerName: providerName,
+		concurrency:  concurrency,
+	}, nil
+}
+
+func (g *GitlabPRClient) GetPullRequests(
+	ctx context.Context,
+	state string,
+	role string,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) ([]*types.PullRequestResponse, error) {
+	var prResponses = make([]*types.PullRequestResponse, 0)
+
+	mrs, err := g.getMRs(ctx, state, role)
+	if err != nil {
+		return prResponses, err
+	}
+
+	var prMutex sync.Mutex
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, len(mrs))
+	respCh := make(chan *types.PullRequestResponse, len(mrs))
+
+	// NOTE: The MRs are started in the background, no more than the concurrency at once, so that their results
+	// are collected meanwhile.
+	limiter := transport.NewLimiter(g.concurrency)
+	go func() {
+		for _, mr := range mrs {
+			if err := limiter.Acquire(ctx); err != nil {
+				errCh <- err
+				break
+			}
+			wg.Add(1)
+
+			go func(mr *types.GitlabMergeRequest) {
+				defer wg.Done()
+				defer limiter.Release()
+
+				prResponse, err := g.getMRDetails(ctx, mr, transformationFn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				respCh <- prResponse
+			}(mr)
+		}
+		wg.Wait()
+		close(respCh)
+		close(errCh)
+	}()
+
+	var errs []error
+
+	for respCh != nil || errCh != nil {
+		select {
+		case resp, ok := <-respCh:
+			if !ok {
+				respCh = nil
+			} else {
+				prMutex.Lock()
+				prResponses = append(prResponses, resp)
+				prMutex.Unlock()
+			}
+		case errValue, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			} else {
+				errMutex.Lock()
+				errs = append(errs, errValue)
+				errMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return prResponses, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	return prResponses, nil
+}
+
+func (g *GitlabPRClient) getMRs(ctx context.Context, state string, role string) ([]*types.GitlabMergeRequest, error) {
+	var gitlabState = "all"
+	if state == "open" {
+		gitlabState = "opened"
+	} else if state == "merged" || state == "closed" {
+		gitlabState = state
+	}
+
+	authored := "scope=created_by_me"
+	reviewing := fmt.Sprintf("scope=all&reviewer_id=%d", g.user.PrincipalID)
+	assigned := "scope=assigned_to_me"
+	var userFilters []string
+	switch role {
+	case RoleAuthor:
+		userFilters = []string{authored}
+	case RoleReviewer:
+		userFilters = []string{reviewing}
+	case RoleAssignee:
+		userFilters = []string{assigned}
+	case RoleInvolved:
+		userFilters = []string{authored, reviewing, assigned}
+	default:
+		return nil, unsupportedRoleError("gitlab", role)
+	}
+
+	var mrs = make([]*types.GitlabMergeRequest, 0)
+	seen := map[string]bool{}
+	for _, userFilter := range userFilters {
+		for page := 1; page != 0; {
+			apiURL := fmt.Sprintf("%s/api/v4/merge_requests?%s&state=%s&order_by=created_at"+
+				"&sort=desc&per_page=100&page=%d", g.host, userFilter, gitlabState, page)
+			pageMRs := make([]*types.GitlabMergeRequest, 0)
+			nextPage, err := gitlab.GetPage(ctx, g.httpClient, g.user.PAT, apiURL, &pageMRs)
+			if err != nil {
+				return mrs, fmt.Errorf("error fetching gitlab merge requests for user %s: %w", g.user.Name, err)
+			}
+			for _, mr := range pageMRs {
+				if !seen[mr.WebURL] {
+					seen[mr.WebURL] = true
+					mrs = append(mrs, mr)
+				}
+			}
+			page = nextPage
+		}
+	}
+	return mrs, nil
+}
+
+func (g *GitlabPRClient) getMRDetails(
+	ctx context.Context,
+	mr *types.GitlabMergeRequest,
+	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
+) (*types.PullRequestResponse, error) {
+	approvals := &types.GitlabApprovals{}
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/approvals", g.host, mr.ProjectID, mr.IID)
+	err := gitlab.Get(ctx, g.httpClient, g.user.PAT, apiURL, approvals)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching MR approvals for %s: %w", mr.WebURL, err)
+	}
+
+	discussions, err := g.getDiscussions(ctx, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	commentedMap := map[string]bool{}
+	decisions := reviewDecisions{}
+	reviewRequested := false
+
+	requestedReviewers := make([]string, 0, len(mr.Reviewers))
+	for _, reviewer := range mr.Reviewers {
+		if reviewer.Username == g.user.Name {
+			reviewRequested = true
+		}
+		requestedReviewers = append(requestedReviewers, reviewer.Username)
+	}
+
+	for _, discussion := range discussions {
+		if len(discussion.Notes) == 0 || discussion.Notes[0].System {
+			continue
+		}
+		// NOTE: GitLab has no first-class "changes requested" review state on every edition, so an unresolved
+		// thread is treated as a change request from the user who opened it.
+		firstNote := discussion.Notes[0]
+		if firstNote.Resolvable && !firstNote.Resolved {
+			decisions.requestChanges(firstNote.Author.Username)
+		}
+		for _, note := range discussion.Notes {
+			if !note.System {
+				commentedMap[note.Author.Username] = true
+			}
+		}
+	}
+
+	// NOTE: The approvals only hold the current ones, GitLab resets them on new commits when the project asks for it.
+	// An approval settles the open threads of the approver.
+	for _, approver := range approvals.ApprovedBy {
+		decisions.approve(approver.User.Username)
+	}
+
+	state := mr.State
+	if state == "opened" || state == "locked" {
+		state = "open"
+	}
+
+	mergeable := "-"
+	if state == "open" {
+		// NOTE: See https://docs.gitlab.com/ee/api/merge_requests.html#merge-status for possible values.
+		mergeable = "false"
+		if mr.DetailedMergeStatus == "mergeable" {
+			mergeable = "true"
+		}
+	}
+
+	rawPR := &types.PullRequest{
+		Title:            mr.Title,
+		Number:           mr.IID,
+		SCMProviderType:  "gitlab",
+		SCMProviderName:  g.providerName,
+		URL:              mr.WebURL,
+		State:            state,
+		Mergeable:        mergeable,
+		Approved:         decisions.approved(),
+		Commented:        mapKeys(commentedMap),
+		RequestedChanges: decisions.changesRequested(),
+		PendingReviewers: getPendingReviewers(requestedReviewers, decisions),
+		MyReview: getMyReview(decisions.hasApproved(g.user.Name), decisions.hasRequestedChanges(g.user.Name),
+			commentedMap[g.user.Name], reviewRequested),
+	}
+
+	printablePR := transformationFn(rawPR)
+
+	return &types.PullRequestResponse{
+		PR:          rawPR,
+		PrintablePR: printablePR,
+	}, nil
+}
+
+func (g *GitlabPRClient) getDiscussions(
+	ctx context.Context,
+	mr *types.GitlabMergeRequest,
+) ([]*types.GitlabDiscussion, error) {
+	var discussions = make([]*types.GitlabDiscussion, 0)
+	for page := 1; page != 0; {
+		apiURL := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/discussions?per_page=100&page=%d",
+			g.host, mr.ProjectID, mr.IID, page)
+		pageDiscussions := make([]*types.GitlabDiscussion, 0)
+		nextPage, err := gitlab.GetPage(ctx, g.httpClient, g.user.PAT, apiURL, &pageDiscussions)
+		if err != nil {
+			return discussions, fmt.Errorf("error fetching MR discussions for %s: %w", mr.WebURL, err)
+		}
+		discussions = append(discussions, pageDiscussions...)
+		page = nextPage
+	}
+	return discussions, nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/dhruv1397/prm/harness"
+	"github.com/dhruv1397/prm/transport"
 	"github.com/dhruv1397/prm/types"
 	"github.com/dhruv1397/prm/util"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -17,131 +19,195 @@ type HarnessPRClient struct {
 	user         *types.User
 	repos        []*types.Repo
 	providerName string
+	// concurrency bounds how many PRs are fetched at once, see clientbuilder.
+	concurrency int
 }
 
 var _ PRClient = (*HarnessPRClient)(nil)
 
-func NewHarnessPRClient(host string, user *types.User, repos []*types.Repo, providerName string) (*HarnessPRClient, error) {
+func NewHarnessPRClient(httpClient *http.Client, host string, user *types.User, repos []*types.Repo, providerName string, concurrency int) (*HarnessPRClient, error) {
 	return &HarnessPRClient{
-		httpClient:   http.DefaultClient,
+		httpClient:   httpClient,
 		host:         host,
 		user:         user,
 		repos:        repos,
 		providerName: providerName,
+		concurrency:  concurrency,
 	}, nil
 }
 
 func (h *HarnessPRClient) GetPullRequests(
 	ctx context.Context,
 	state string,
+	role string,
 	transformationFn func(*types.PullRequest) *types.PrintablePullRequest,
 ) ([]*types.PullRequestResponse, error) {
 	var allPullRequests []*types.PullRequestResponse
+	userFilters, err := getHarnessUserFilters(role)
+	if err != nil {
+		return allPullRequests, err
+	}
 	var prMutex sync.Mutex
 	var errMutex sync.Mutex
 
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(h.repos)*500)
-	prChan := make(chan *types.PullRequestResponse, len(h.repos)*500)
-
-	for _, repo := range h.repos {
-		wg.Add(1)
-		go func(repo *types.Repo) {
-			defer wg.Done()
+	errChan := make(chan error)
+	prChan := make(chan *types.PullRequestResponse)
 
-			prs, err := h.getPRs(ctx, repo, state)
-			if err != nil {
+	// NOTE: The repos are started in the background, no more than the concurrency at once, so that their results are
+	// collected meanwhile. The PRs of all the repos share a second limiter, as a repo holding its slot while waiting on
+	// its PRs would otherwise starve them.
+	repoLimiter := transport.NewLimiter(h.concurrency)
+	prLimiter := transport.NewLimiter(h.concurrency)
+	go func() {
+		for _, repo := range h.repos {
+			if err := repoLimiter.Acquire(ctx); err != nil {
 				errChan <- err
-				return
+				break
 			}
+			wg.Add(1)
+			go func(repo *types.Repo) {
+				defer wg.Done()
+				defer repoLimiter.Release()
 
-			var prWg sync.WaitGroup
-			for _, pr := range prs {
-				prWg.Add(1)
-				go func(pr *types.PRData) {
-					defer prWg.Done()
+				prs, err := h.getPRs(ctx, repo, state, userFilters)
+				if err != nil {
+					errChan <- err
+					return
+				}
 
-					prActivities, err := h.getPRActivities(ctx, repo, pr)
-					if err != nil {
+				var prWg sync.WaitGroup
+				for _, pr := range prs {
+					if err := prLimiter.Acquire(ctx); err != nil {
 						errChan <- err
-						return
+						break
 					}
+					prWg.Add(1)
+					go func(pr *types.PRData) {
+						defer prWg.Done()
+						defer prLimiter.Release()
 
-					approvedMap := map[string]bool{}
-					commentedMap := map[string]bool{}
-					changesRequestedMap := map[string]bool{}
-					for _, prActivity := range prActivities {
-						if (prActivity.Type == "code-comment" || prActivity.Type == "comment") &&
-							!commentedMap[prActivity.PRActivityAuthor.DisplayName] {
+						prActivities, err := h.getPRActivities(ctx, repo, pr)
+						if err != nil {
+							errChan <- err
+							return
+						}
+
+						commentedMap := map[string]bool{}
+						for _, prActivity := range prActivities {
 							commentedMap[prActivity.PRActivityAuthor.DisplayName] = true
-						} else if prActivity.Type == "review-submit" && *prActivity.PRActivityDecision.Decision == "approved" &&
-							!approvedMap[prActivity.PRActivityAuthor.DisplayName] {
-							approvedMap[prActivity.PRActivityAuthor.DisplayName] = true
-						} else if prActivity.Type == "review-submit" && *prActivity.PRActivityDecision.Decision == "changereq" &&
-							!changesRequestedMap[prActivity.PRActivityAuthor.DisplayName] {
-							changesRequestedMap[prActivity.PRActivityAuthor.DisplayName] = true
 						}
-					}
 
-					approved := make([]string, 0, len(approvedMap))
-					for k := range approvedMap {
-						approved = append(approved, k)
-					}
+						reviewers, err := h.getReviewers(ctx, repo, pr)
+						if err != nil {
+							errChan <- err
+							return
+						}
 
-					commented := make([]string, 0, len(commentedMap))
-					for k := range commentedMap {
-						commented = append(commented, k)
-					}
+						// NOTE: The reviewers hold the latest decision of each reviewer along with the commit it was made
+						// on, an approval of an outdated commit no longer vouches for the PR and the reviewer has to review it
+						// again.
+						decisions := reviewDecisions{}
+						pendingReviewers := make([]string, 0)
+						myReview := ""
+						for _, reviewer := range reviewers {
+							userName := reviewer.Reviewer.DisplayName
+							pending := false
+							switch reviewer.ReviewDecision {
+							case "approved":
+								if reviewer.SHA == pr.SourceSHA {
+									decisions.approve(userName)
+								} else {
+									pending = true
+								}
+							case "changereq":
+								decisions.requestChanges(userName)
+							case "pending":
+								pending = true
+							}
+							if pending {
+								pendingReviewers = append(pendingReviewers, userName)
+							}
+							if reviewer.Reviewer.ID == h.user.PrincipalID {
+								myReview = getMyReview(decisions.hasApproved(userName), decisions.hasRequestedChanges(userName),
+									reviewer.ReviewDecision == "reviewed" || commentedMap[userName], pending)
+							}
+						}
 
-					changesRequested := make([]string, 0, len(changesRequestedMap))
-					for k := range changesRequestedMap {
-						changesRequested = append(changesRequested, k)
-					}
+						approved := decisions.approved()
+						commented := mapKeys(commentedMap)
+						changesRequested := decisions.changesRequested()
 
-					url := h.getHarnessPRURL(pr.Number, repo)
+						url := h.getHarnessPRURL(pr.Number, repo)
 
-					mergeable := "-"
-					if pr.State != "merged" {
-						mergeable = strconv.FormatBool(pr.MergeCheckStatus == "mergeable")
-						if mergeable == "true" {
-							rulesPassed, rulesErr := h.getPRMergeDetails(ctx, repo, pr)
-							if rulesErr != nil {
-								errChan <- rulesErr
-								return
+						mergeable := "-"
+						if pr.State != "merged" {
+							mergeable = strconv.FormatBool(pr.MergeCheckStatus == "mergeable")
+							if mergeable == "true" {
+								rulesPassed, rulesErr := h.getPRMergeDetails(ctx, repo, pr)
+								if rulesErr != nil {
+									errChan <- rulesErr
+									return
+								}
+								if !rulesPassed {
+									mergeable = "false"
+								}
 							}
-							if !rulesPassed {
-								mergeable = "false"
+						}
+
+						var checks *types.ChecksSummary
+						if pr.State == "open" {
+							checks, err = h.getChecks(ctx, repo, pr)
+							if err != nil {
+								errChan <- err
+								return
 							}
 						}
-					}
 
-					currentPullRequest := &types.PullRequest{
-						Number:           pr.Number,
-						Title:            pr.Title,
-						SCMProviderType:  "harness",
-						SCMProviderName:  h.providerName,
-						URL:              url,
-						Approved:         approved,
-						Commented:        commented,
-						RequestedChanges: changesRequested,
-						Mergeable:        mergeable,
-						State:            pr.State,
-					}
+						labels := make([]string, 0, len(pr.Labels))
+						for _, label := range pr.Labels {
+							labels = append(labels, label.Key)
+						}
 
-					printablePR := transformationFn(currentPullRequest)
+						currentPullRequest := &types.PullRequest{
+							Number:           pr.Number,
+							Title:            pr.Title,
+							SCMProviderType:  "harness",
+							SCMProviderName:  h.providerName,
+							URL:              url,
+							Approved:         approved,
+							Commented:        commented,
+							RequestedChanges: changesRequested,
+							PendingReviewers: pendingReviewers,
+							Mergeable:        mergeable,
+							State:            pr.State,
+							MyReview:         myReview,
+							Checks:           checks,
+							Draft:            pr.IsDraft,
+							Labels:           labels,
+							Repo:             getHarnessRepoName(repo),
+							SourceBranch:     pr.SourceBranch,
+							TargetBranch:     pr.TargetBranch,
+							Author:           pr.Author.DisplayName,
+							Created:          pr.Created,
+							Updated:          pr.Updated,
+						}
+						if pr.Merged != nil {
+							currentPullRequest.Merged = *pr.Merged
+						}
 
-					prChan <- &types.PullRequestResponse{
-						PR:          currentPullRequest,
-						PrintablePR: printablePR,
-					}
-				}(pr)
-			}
+						printablePR := transformationFn(currentPullRequest)
 
-			prWg.Wait()
-		}(repo)
-	}
+						prChan <- &types.PullRequestResponse{
+							PR:          currentPullRequest,
+							PrintablePR: printablePR,
+						}
+					}(pr)
+				}
 
-	go func() {
+				prWg.Wait()
+			}(repo)
+		}
 		wg.Wait()
 		close(prChan)
 		close(errChan)
@@ -183,19 +249,80 @@ func (h *HarnessPRClient) getHarnessPRURL(prNumber int, repo *types.Repo) string
 		repo.RepoIdentifier, "/pulls/", prNumber)
 }
 
-func (h *HarnessPRClient) getPRs(ctx context.Context, repo *types.Repo, state string) ([]*types.PRData, error) {
+// getHarnessRepoName returns the path of the repo below the account, repos may live at account or org level too.
+func getHarnessRepoName(repo *types.Repo) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{repo.OrgIdentifier, repo.ProjectIdentifier, repo.RepoIdentifier} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// getHarnessUserFilters returns the query parameters matching the PRs in which the user has the role, the PRs
+// matching any of them are listed.
+func getHarnessUserFilters(role string) ([]string, error) {
+	switch role {
+	case RoleAuthor:
+		return []string{"created_by"}, nil
+	case RoleReviewer:
+		return []string{"reviewer_id"}, nil
+	case RoleInvolved:
+		return []string{"created_by", "reviewer_id"}, nil
+	default:
+		return nil, unsupportedRoleError("harness", role)
+	}
+}
+
+func (h *HarnessPRClient) getPRs(
+	ctx context.Context,
+	repo *types.Repo,
+	state string,
+	userFilters []string,
+) ([]*types.PRData, error) {
 	var prs = make([]*types.PRData, 0)
-	apiURL := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%d%s", h.host, "/code/api/v1/repos/", repo.RepoIdentifier,
-		"/pullreq?accountIdentifier=", repo.AccountIdentifier, "&orgIdentifier=", repo.OrgIdentifier,
-		"&projectIdentifier=", repo.ProjectIdentifier, "&state=", state, "&page=0&limit=500&created_by=",
-		h.user.PrincipalID, "&order=desc")
-	err := harness.Get(ctx, h.httpClient, h.user.PAT, apiURL, &prs)
-	if err != nil {
-		return prs, fmt.Errorf("error fetching PRs for repo %s: %w", repo.RepoIdentifier, err)
+	seen := map[int]bool{}
+	for _, userFilter := range userFilters {
+		for page := 1; page != harness.NoNextPage; {
+			apiURL := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%d%s%d%s%s%s%d%s", h.host, "/code/api/v1/repos/",
+				repo.RepoIdentifier, "/pullreq?accountIdentifier=", repo.AccountIdentifier, "&orgIdentifier=",
+				repo.OrgIdentifier, "&projectIdentifier=", repo.ProjectIdentifier, "&state=", state, "&page=", page,
+				"&limit=", harness.PageLimit, "&", userFilter, "=", h.user.PrincipalID, "&order=desc")
+			pagePRs := make([]*types.PRData, 0)
+			nextPage, err := harness.GetPage(ctx, h.httpClient, h.user.PAT, apiURL, page, &pagePRs)
+			if err != nil {
+				return prs, fmt.Errorf("error fetching PRs for repo %s: %w", repo.RepoIdentifier, err)
+			}
+			for _, pr := range pagePRs {
+				if !seen[pr.Number] {
+					seen[pr.Number] = true
+					prs = append(prs, pr)
+				}
+			}
+			page = nextPage
+		}
 	}
 	return prs, nil
 }
 
+// getReviewers returns the reviewers of the PR along with their latest decision and the commit it was made on.
+func (h *HarnessPRClient) getReviewers(
+	ctx context.Context,
+	repo *types.Repo,
+	pr *types.PRData,
+) ([]*types.PRReviewer, error) {
+	var reviewers = make([]*types.PRReviewer, 0)
+	apiURL := fmt.Sprintf("%s%s%s%s%d%s%s%s%s%s%s", h.host, "/code/api/v1/repos/", repo.RepoIdentifier,
+		"/pullreq/", pr.Number, "/reviewers?accountIdentifier=", repo.AccountIdentifier, "&orgIdentifier=",
+		repo.OrgIdentifier, "&projectIdentifier=", repo.ProjectIdentifier)
+	err := harness.Get(ctx, h.httpClient, h.user.PAT, apiURL, &reviewers)
+	if err != nil {
+		return reviewers, fmt.Errorf("error fetching PR reviewers for %s: %w", h.getHarnessPRURL(pr.Number, repo), err)
+	}
+	return reviewers, nil
+}
+
 func (h *HarnessPRClient) getPRActivities(
 	ctx context.Context,
 	repo *types.Repo,
@@ -204,7 +331,7 @@ func (h *HarnessPRClient) getPRActivities(
 	var prActivities = make([]*types.PRActivity, 0)
 	apiURL := fmt.Sprintf("%s%s%s%s%d%s%s%s%s%s%s%s", h.host, "/code/api/v1/repos/", repo.RepoIdentifier,
 		"/pullreq/", pr.Number, "/activities?accountIdentifier=", repo.AccountIdentifier, "&orgIdentifier=",
-		repo.OrgIdentifier, "&projectIdentifier=", repo.ProjectIdentifier, "&type=code-comment&type=comment&type=review-submit")
+		repo.OrgIdentifier, "&projectIdentifier=", repo.ProjectIdentifier, "&type=code-comment&type=comment")
 	err := harness.Get(ctx, h.httpClient, h.user.PAT, apiURL, &prActivities)
 	if err != nil {
 		return prActivities, fmt.Errorf("error fetching PR activities for %s: %w",
@@ -213,6 +340,27 @@ func (h *HarnessPRClient) getPRActivities(
 	return prActivities, nil
 }
 
+func (h *HarnessPRClient) getChecks(
+	ctx context.Context,
+	repo *types.Repo,
+	pr *types.PRData,
+) (*types.ChecksSummary, error) {
+	var prChecks = types.PRChecks{}
+	apiURL := fmt.Sprintf("%s%s%s%s%d%s%s%s%s%s%s", h.host, "/code/api/v1/repos/", repo.RepoIdentifier,
+		"/pullreq/", pr.Number, "/checks?accountIdentifier=", repo.AccountIdentifier, "&orgIdentifier=",
+		repo.OrgIdentifier, "&projectIdentifier=", repo.ProjectIdentifier)
+	err := harness.Get(ctx, h.httpClient, h.user.PAT, apiURL, &prChecks)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching PR checks for %s: %w", h.getHarnessPRURL(pr.Number, repo), err)
+	}
+
+	checks := newChecksSummary()
+	for _, prCheck := range prChecks.Checks {
+		addCheck(checks, prCheck.Check.Identifier, getHarnessCheckOutcome(prCheck.Check.Status))
+	}
+	return checks, nil
+}
+
 func (h *HarnessPRClient) getPRMergeDetails(
 	ctx context.Context,
 	repo *types.Repo,
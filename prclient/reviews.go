@@ -0,0 +1,74 @@
+package prclient
+
+import "slices"
+
+// reviewDecisions keeps the latest verdict of every reviewer on a PR, true when they approve and false when they request
+// changes. The reviews have to be added oldest first so that a later verdict replaces an earlier one.
+type reviewDecisions map[string]bool
+
+func (d reviewDecisions) approve(reviewer string) {
+	d[reviewer] = true
+}
+
+func (d reviewDecisions) requestChanges(reviewer string) {
+	d[reviewer] = false
+}
+
+// dismiss drops the verdict of the reviewer, eg when it was dismissed or when the approval is on an outdated commit.
+func (d reviewDecisions) dismiss(reviewer string) {
+	delete(d, reviewer)
+}
+
+func (d reviewDecisions) hasApproved(reviewer string) bool {
+	approved, ok := d[reviewer]
+	return ok && approved
+}
+
+func (d reviewDecisions) hasRequestedChanges(reviewer string) bool {
+	approved, ok := d[reviewer]
+	return ok && !approved
+}
+
+func (d reviewDecisions) approved() []string {
+	return d.reviewers(true)
+}
+
+func (d reviewDecisions) changesRequested() []string {
+	return d.reviewers(false)
+}
+
+func (d reviewDecisions) reviewers(approved bool) []string {
+	reviewers := make([]string, 0, len(d))
+	for reviewer, decision := range d {
+		if decision == approved {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	slices.Sort(reviewers)
+	return reviewers
+}
+
+// getPendingReviewers returns the requested reviewers who have not given their verdict yet, for the providers which
+// keep reviewers in the list of requested ones after they have reviewed.
+func getPendingReviewers(requested []string, decisions reviewDecisions) []string {
+	pending := make([]string, 0, len(requested))
+	for _, reviewer := range requested {
+		if _, ok := decisions[reviewer]; !ok && !slices.Contains(pending, reviewer) {
+			pending = append(pending, reviewer)
+		}
+	}
+	return pending
+}
+
+// addStaleApprovers appends the reviewers whose latest verdict is an approval of an outdated commit to the pending ones,
+// as they have to review the PR again.
+func addStaleApprovers(pending []string, staleApprovers map[string]bool) []string {
+	stale := make([]string, 0, len(staleApprovers))
+	for reviewer, isStale := range staleApprovers {
+		if isStale && !slices.Contains(pending, reviewer) {
+			stale = append(stale, reviewer)
+		}
+	}
+	slices.Sort(stale)
+	return append(pending, stale...)
+}
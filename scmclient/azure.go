@@ -0,0 +1,234 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/azure"
+	"github.com/dhruv1397/prm/transport"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const azurePageLimit = 500
+
+type AzureSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	host       string
+	// concurrency bounds how many projects are fetched at once, see clientbuilder.
+	concurrency int
+}
+
+func NewAzureSCMClient(httpClient *http.Client, host string, pat string, concurrency int) (*AzureSCMClient, error) {
+	return &AzureSCMClient{
+		httpClient:  httpClient,
+		pat:         pat,
+		host:        host,
+		concurrency: concurrency,
+	}, nil
+}
+
+func (a *AzureSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching azure devops user details...")
+	user := &types.User{PAT: a.pat}
+	if azure.IsCloud(a.host) {
+		profile, err := a.getProfile(ctx)
+		if err != nil {
+			return nil, err
+		}
+		user.Name = profile.DisplayName
+		user.Email = profile.EmailAddress
+		user.AccountID = profile.ID
+	} else {
+		apiURL := fmt.Sprintf("%s%s%s", a.host, "/_apis/connectionData?api-version=", azure.APIVersion)
+		responseObj := &types.AzureConnectionData{}
+		err := azure.Get(ctx, a.httpClient, a.pat, apiURL, responseObj)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching authenticated azure devops user: %w", err)
+		}
+		user.Name = responseObj.AuthenticatedUser.ProviderDisplayName
+		user.AccountID = responseObj.AuthenticatedUser.ID
+	}
+	fmt.Println("Successfully fetched azure devops user details!")
+	return user, nil
+}
+
+func (a *AzureSCMClient) GetRepos(ctx context.Context) ([]*types.Repo, error) {
+	fmt.Println("Fetching azure devops repositories...")
+	orgs, err := a.getOrgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var allRepos []*types.Repo
+	var repoMutex sync.Mutex
+	var repoErrMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	errChan := make(chan error)
+	repoChan := make(chan *types.Repo)
+
+	// NOTE: The orgs are started in the background, no more than the concurrency at once, so that their results are
+	// collected meanwhile. The projects of all the orgs share a second limiter, as an org holding its slot while waiting
+	// on its projects would otherwise starve them.
+	orgLimiter := transport.NewLimiter(a.concurrency)
+	projectLimiter := transport.NewLimiter(a.concurrency)
+	go func() {
+		for _, org := range orgs {
+			if err := orgLimiter.Acquire(ctx); err != nil {
+				errChan <- err
+				break
+			}
+			wg.Add(1)
+			go func(org string) {
+				defer wg.Done()
+				defer orgLimiter.Release()
+
+				projects, err := a.getProjects(ctx, org)
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				var projectWg sync.WaitGroup
+				for _, project := range projects {
+					if err := projectLimiter.Acquire(ctx); err != nil {
+						errChan <- err
+						break
+					}
+					projectWg.Add(1)
+					go func(project string) {
+						defer projectWg.Done()
+						defer projectLimiter.Release()
+
+						repos, err := a.getRepos(ctx, org, project)
+						if err != nil {
+							errChan <- err
+							return
+						}
+
+						for _, repo := range repos {
+							currentRepo := &types.Repo{
+								OrgIdentifier:     org,
+								ProjectIdentifier: project,
+								RepoIdentifier:    repo,
+							}
+							repoChan <- currentRepo
+						}
+					}(project)
+				}
+
+				projectWg.Wait()
+			}(org)
+		}
+		wg.Wait()
+		close(repoChan)
+		close(errChan)
+	}()
+
+	var errs []error
+
+	for repoChan != nil || errChan != nil {
+		select {
+		case resp, ok := <-repoChan:
+			if !ok {
+				repoChan = nil
+			} else {
+				repoMutex.Lock()
+				allRepos = append(allRepos, resp)
+				repoMutex.Unlock()
+			}
+		case errValue, ok := <-errChan:
+			if !ok {
+				errChan = nil
+			} else {
+				repoErrMutex.Lock()
+				errs = append(errs, errValue)
+				repoErrMutex.Unlock()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return allRepos, fmt.Errorf("errors encountered:\n%v", util.FormatErrors(errs))
+	}
+
+	fmt.Printf("Successfully fetched %d azure devops repositories!\n", len(allRepos))
+
+	return allRepos, nil
+}
+
+func (a *AzureSCMClient) getProfile(ctx context.Context) (*types.AzureProfile, error) {
+	apiURL := fmt.Sprintf("%s%s%s", azure.ProfileURL(), "/_apis/profile/profiles/me?api-version=", azure.APIVersion)
+	responseObj := &types.AzureProfile{}
+	err := azure.Get(ctx, a.httpClient, a.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching azure devops user profile: %w", err)
+	}
+	return responseObj, nil
+}
+
+// getOrgs lists the organizations the user is a member of. Azure DevOps Server hosts a single collection, which is
+// represented by an empty organization.
+func (a *AzureSCMClient) getOrgs(ctx context.Context) ([]string, error) {
+	var orgs = make([]string, 0)
+	if !azure.IsCloud(a.host) {
+		return append(orgs, ""), nil
+	}
+
+	profile, err := a.getProfile(ctx)
+	if err != nil {
+		return orgs, err
+	}
+	apiURL := fmt.Sprintf("%s%s%s%s%s", azure.ProfileURL(), "/_apis/accounts?memberId=", profile.ID,
+		"&api-version=", azure.APIVersion)
+	responseObj := &types.AzureAccountList{}
+	err = azure.Get(ctx, a.httpClient, a.pat, apiURL, responseObj)
+	if err != nil {
+		return orgs, fmt.Errorf("error fetching azure devops organizations for user %s: %w", profile.DisplayName, err)
+	}
+	for _, account := range responseObj.Value {
+		orgs = append(orgs, account.AccountName)
+	}
+	return orgs, nil
+}
+
+func (a *AzureSCMClient) getProjects(ctx context.Context, org string) ([]string, error) {
+	var projects = make([]string, 0)
+	for skip := 0; ; skip += azurePageLimit {
+		apiURL := fmt.Sprintf("%s/_apis/projects?$top=%d&$skip=%d&api-version=%s", azure.OrgURL(a.host, org),
+			azurePageLimit, skip, azure.APIVersion)
+		responseObj := &types.AzureProjectList{}
+		err := azure.Get(ctx, a.httpClient, a.pat, apiURL, responseObj)
+		if err != nil {
+			return projects, fmt.Errorf("error fetching azure devops projects for org %s: %w", org, err)
+		}
+		for _, project := range responseObj.Value {
+			projects = append(projects, project.Name)
+		}
+		if len(responseObj.Value) < azurePageLimit {
+			break
+		}
+	}
+	return projects, nil
+}
+
+func (a *AzureSCMClient) getRepos(ctx context.Context, org string, project string) ([]string, error) {
+	var repos = make([]string, 0)
+	apiURL := fmt.Sprintf("%s/%s/_apis/git/repositories?api-version=%s", azure.OrgURL(a.host, org),
+		url.PathEscape(project), azure.APIVersion)
+	responseObj := &types.AzureRepositoryList{}
+	err := azure.Get(ctx, a.httpClient, a.pat, apiURL, responseObj)
+	if err != nil {
+		return repos, fmt.Errorf("error fetching azure devops repos for org %s & project %s: %w", org, project, err)
+	}
+	for _, repo := range responseObj.Value {
+		if !repo.IsDisabled {
+			repos = append(repos, repo.Name)
+		}
+	}
+	return repos, nil
+}
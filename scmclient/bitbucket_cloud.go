@@ -0,0 +1,39 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/bitbucket"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+)
+
+type BitbucketCloudSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	apiURL     string
+}
+
+func NewBitbucketCloudSCMClient(httpClient *http.Client, host string, pat string) (*BitbucketCloudSCMClient, error) {
+	return &BitbucketCloudSCMClient{
+		httpClient: httpClient,
+		pat:        pat,
+		apiURL:     bitbucket.CloudAPIURL(host),
+	}, nil
+}
+
+func (b *BitbucketCloudSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching bitbucket cloud user details...")
+	apiURL := fmt.Sprintf("%s%s", b.apiURL, "/user")
+	responseObj := &types.BitbucketCloudUser{}
+	err := bitbucket.Get(ctx, b.httpClient, b.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching authenticated bitbucket cloud user: %w", err)
+	}
+	fmt.Println("Successfully fetched bitbucket cloud user details!")
+	return &types.User{
+		Name:      responseObj.Nickname,
+		AccountID: responseObj.AccountID,
+		PAT:       b.pat,
+	}, nil
+}
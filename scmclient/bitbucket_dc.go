@@ -0,0 +1,53 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/bitbucket"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+	"net/url"
+)
+
+type BitbucketDCSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	apiURL     string
+}
+
+func NewBitbucketDCSCMClient(httpClient *http.Client, host string, pat string) (*BitbucketDCSCMClient, error) {
+	return &BitbucketDCSCMClient{
+		httpClient: httpClient,
+		pat:        pat,
+		apiURL:     bitbucket.DataCenterAPIURL(host),
+	}, nil
+}
+
+func (b *BitbucketDCSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching bitbucket data center user details...")
+	// NOTE: Data Center has no "current user" endpoint, every authenticated response carries the username in the
+	// X-AUSERNAME header instead.
+	apiURL := fmt.Sprintf("%s%s", b.apiURL, "/application-properties")
+	header, err := bitbucket.GetWithHeader(ctx, b.httpClient, b.pat, apiURL, &map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching authenticated bitbucket data center user: %w", err)
+	}
+	userName := header.Get("X-AUSERNAME")
+	if userName == "" {
+		return nil, fmt.Errorf("error fetching authenticated bitbucket data center user: request was not authenticated")
+	}
+
+	apiURL = fmt.Sprintf("%s%s%s", b.apiURL, "/users/", url.PathEscape(userName))
+	responseObj := &types.BitbucketDCUser{}
+	err = bitbucket.Get(ctx, b.httpClient, b.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching bitbucket data center user %s: %w", userName, err)
+	}
+	fmt.Println("Successfully fetched bitbucket data center user details!")
+	return &types.User{
+		Name:        responseObj.Name,
+		PrincipalID: responseObj.ID,
+		Email:       responseObj.EmailAddress,
+		PAT:         b.pat,
+	}, nil
+}
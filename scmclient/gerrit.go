@@ -0,0 +1,40 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gerrit"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+)
+
+type GerritSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	host       string
+}
+
+func NewGerritSCMClient(httpClient *http.Client, host string, pat string) (*GerritSCMClient, error) {
+	return &GerritSCMClient{
+		httpClient: httpClient,
+		pat:        pat,
+		host:       host,
+	}, nil
+}
+
+func (g *GerritSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching gerrit user details...")
+	apiURL := fmt.Sprintf("%s%s", g.host, "/a/accounts/self")
+	responseObj := &types.GerritAccount{}
+	err := gerrit.Get(ctx, g.httpClient, g.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching authenticated gerrit user: %w", err)
+	}
+	fmt.Println("Successfully fetched gerrit user details!")
+	return &types.User{
+		Name:        responseObj.Username,
+		PrincipalID: responseObj.AccountID,
+		Email:       responseObj.Email,
+		PAT:         g.pat,
+	}, nil
+}
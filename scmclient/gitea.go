@@ -0,0 +1,40 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gitea"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+)
+
+type GiteaSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	host       string
+}
+
+func NewGiteaSCMClient(httpClient *http.Client, host string, pat string) (*GiteaSCMClient, error) {
+	return &GiteaSCMClient{
+		httpClient: httpClient,
+		pat:        pat,
+		host:       host,
+	}, nil
+}
+
+func (g *GiteaSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching gitea user details...")
+	apiURL := fmt.Sprintf("%s%s", g.host, "/api/v1/user")
+	responseObj := &types.GiteaUser{}
+	err := gitea.Get(ctx, g.httpClient, g.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching authenticated gitea user: %w", err)
+	}
+	fmt.Println("Successfully fetched gitea user details!")
+	return &types.User{
+		Name:        responseObj.Login,
+		PrincipalID: responseObj.ID,
+		Email:       responseObj.Email,
+		PAT:         g.pat,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package scmclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/dhruv1397/prm/gitlab"
+	"github.com/dhruv1397/prm/types"
+	"net/http"
+)
+
+type GitlabSCMClient struct {
+	httpClient *http.Client
+	pat        string
+	host       string
+}
+
+func NewGitlabSCMClient(httpClient *http.Client, host string, pat string) (*GitlabSCMClient, error) {
+	return &GitlabSCMClient{
+		httpClient: httpClient,
+		pat:        pat,
+		host:       host,
+	}, nil
+}
+
+func (g *GitlabSCMClient) GetUser(ctx context.Context) (*types.User, error) {
+	fmt.Println("Fetching gitlab user details...")
+	apiURL := fmt.Sprintf("%s%s", g.host, "/api/v4/user")
+	responseObj := &types.GitlabUser{}
+	err := gitlab.Get(ctx, g.httpClient, g.pat, apiURL, responseObj)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching authenticated gitlab user: %w", err)
+	}
+	fmt.Println("Successfully fetched gitlab user details!")
+	return &types.User{
+		Name:        responseObj.Username,
+		PrincipalID: responseObj.ID,
+		Email:       responseObj.Email,
+		PAT:         g.pat,
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/dhruv1397/prm/harness"
+	"github.com/dhruv1397/prm/transport"
 	"github.com/dhruv1397/prm/types"
 	"github.com/dhruv1397/prm/util"
 	"net/http"
@@ -16,15 +17,18 @@ type HarnessSCMClient struct {
 	pat               string
 	accountIdentifier string
 	host              string
+	// concurrency bounds how many projects are fetched at once, see clientbuilder.
+	concurrency int
 }
 
-func NewHarnessSCMClient(host string, pat string) (*HarnessSCMClient, error) {
+func NewHarnessSCMClient(httpClient *http.Client, host string, pat string, concurrency int) (*HarnessSCMClient, error) {
 	parts := strings.Split(pat, ".")
 	return &HarnessSCMClient{
 		accountIdentifier: parts[1],
-		httpClient:        http.DefaultClient,
+		httpClient:        httpClient,
 		pat:               pat,
 		host:              host,
+		concurrency:       concurrency,
 	}, nil
 }
 
@@ -58,49 +62,63 @@ func (h *HarnessSCMClient) GetRepos(ctx context.Context) ([]*types.Repo, error)
 	var repoErrMutex sync.Mutex
 
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(orgs)*200)
-	repoChan := make(chan *types.Repo, len(orgs)*200)
-
-	for _, org := range orgs {
-		wg.Add(1)
-		go func(org string) {
-			defer wg.Done()
-
-			projects, err := h.getProjects(ctx, org)
-			if err != nil {
+	errChan := make(chan error)
+	repoChan := make(chan *types.Repo)
+
+	// NOTE: The orgs are started in the background, no more than the concurrency at once, so that their results are
+	// collected meanwhile. The projects of all the orgs share a second limiter, as an org holding its slot while waiting
+	// on its projects would otherwise starve them.
+	orgLimiter := transport.NewLimiter(h.concurrency)
+	projectLimiter := transport.NewLimiter(h.concurrency)
+	go func() {
+		for _, org := range orgs {
+			if err := orgLimiter.Acquire(ctx); err != nil {
 				errChan <- err
-				return
+				break
 			}
-
-			var projectWg sync.WaitGroup
-			for _, project := range projects {
-				projectWg.Add(1)
-				go func(project string) {
-					defer projectWg.Done()
-
-					repos, err := h.getRepos(ctx, org, project)
-					if err != nil {
+			wg.Add(1)
+			go func(org string) {
+				defer wg.Done()
+				defer orgLimiter.Release()
+
+				projects, err := h.getProjects(ctx, org)
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				var projectWg sync.WaitGroup
+				for _, project := range projects {
+					if err := projectLimiter.Acquire(ctx); err != nil {
 						errChan <- err
-						return
+						break
 					}
-
-					for _, repo := range repos {
-						currentRepo := &types.Repo{
-							AccountIdentifier: h.accountIdentifier,
-							OrgIdentifier:     org,
-							ProjectIdentifier: project,
-							RepoIdentifier:    repo,
+					projectWg.Add(1)
+					go func(project string) {
+						defer projectWg.Done()
+						defer projectLimiter.Release()
+
+						repos, err := h.getRepos(ctx, org, project)
+						if err != nil {
+							errChan <- err
+							return
 						}
-						repoChan <- currentRepo
-					}
-				}(project)
-			}
 
-			projectWg.Wait()
-		}(org)
-	}
+						for _, repo := range repos {
+							currentRepo := &types.Repo{
+								AccountIdentifier: h.accountIdentifier,
+								OrgIdentifier:     org,
+								ProjectIdentifier: project,
+								RepoIdentifier:    repo,
+							}
+							repoChan <- currentRepo
+						}
+					}(project)
+				}
 
-	go func() {
+				projectWg.Wait()
+			}(org)
+		}
 		wg.Wait()
 		close(repoChan)
 		close(errChan)
@@ -162,45 +180,58 @@ func (h *HarnessSCMClient) getPrincipalID(ctx context.Context, email string) (in
 
 func (h *HarnessSCMClient) getOrgs(ctx context.Context) ([]string, error) {
 	var orgs = make([]string, 0)
-	apiURL := fmt.Sprintf("%s%s", h.host, "/v1/orgs?page=0&limit=200&sort=name&order=ASC")
-	responseObj := make([]*types.OrgResponse, 0)
-	err := harness.Get(ctx, h.httpClient, h.pat, apiURL, &responseObj)
-	if err != nil {
-		return orgs, fmt.Errorf("error fetching harness orgs for account %s: %w", h.accountIdentifier, err)
-	}
-	for _, org := range responseObj {
-		orgs = append(orgs, org.OrgData.Identifier)
+	for page := 0; page != harness.NoNextPage; {
+		apiURL := fmt.Sprintf("%s%s%d%s%d%s", h.host, "/v1/orgs?page=", page, "&limit=", harness.PageLimit,
+			"&sort=name&order=ASC")
+		responseObj := make([]*types.OrgResponse, 0)
+		nextPage, err := harness.GetPage(ctx, h.httpClient, h.pat, apiURL, page, &responseObj)
+		if err != nil {
+			return orgs, fmt.Errorf("error fetching harness orgs for account %s: %w", h.accountIdentifier, err)
+		}
+		for _, org := range responseObj {
+			orgs = append(orgs, org.OrgData.Identifier)
+		}
+		page = nextPage
 	}
 	return orgs, nil
 }
 
 func (h *HarnessSCMClient) getProjects(ctx context.Context, org string) ([]string, error) {
 	var projects = make([]string, 0)
-	apiURL := fmt.Sprintf("%s%s%s%s", h.host, "/v1/orgs/", org, "/projects?has_module=true&module_type=CODE&page=0&limit=200&sort=name&order=ASC")
-	responseObj := make([]*types.ProjectResponse, 0)
-	err := harness.Get(ctx, h.httpClient, h.pat, apiURL, &responseObj)
-	if err != nil {
-		return projects, fmt.Errorf("error fetching harness projects for account %s & org %s: %w",
-			h.accountIdentifier, org, err)
-	}
-	for _, project := range responseObj {
-		projects = append(projects, project.ProjectData.Identifier)
+	for page := 0; page != harness.NoNextPage; {
+		apiURL := fmt.Sprintf("%s%s%s%s%d%s%d%s", h.host, "/v1/orgs/", org,
+			"/projects?has_module=true&module_type=CODE&page=", page, "&limit=", harness.PageLimit,
+			"&sort=name&order=ASC")
+		responseObj := make([]*types.ProjectResponse, 0)
+		nextPage, err := harness.GetPage(ctx, h.httpClient, h.pat, apiURL, page, &responseObj)
+		if err != nil {
+			return projects, fmt.Errorf("error fetching harness projects for account %s & org %s: %w",
+				h.accountIdentifier, org, err)
+		}
+		for _, project := range responseObj {
+			projects = append(projects, project.ProjectData.Identifier)
+		}
+		page = nextPage
 	}
 	return projects, nil
 }
 
 func (h *HarnessSCMClient) getRepos(ctx context.Context, org string, project string) ([]string, error) {
 	var repos = make([]string, 0)
-	apiURL := fmt.Sprintf("%s%s%s%s%s%s%s%s", h.host, "/code/api/v1/repos?accountIdentifier=",
-		h.accountIdentifier, "&orgIdentifier=", org, "&projectIdentifier=", project, "&page=1&limit=200")
-	responseObj := make([]*types.RepoData, 0)
-	err := harness.Get(ctx, h.httpClient, h.pat, apiURL, &responseObj)
-	if err != nil {
-		return repos, fmt.Errorf("error fetching harness repos for account %s , org %s & project %s: %w",
-			h.accountIdentifier, org, project, err)
-	}
-	for _, repo := range responseObj {
-		repos = append(repos, repo.Identifier)
+	for page := 1; page != harness.NoNextPage; {
+		apiURL := fmt.Sprintf("%s%s%s%s%s%s%s%s%d%s%d", h.host, "/code/api/v1/repos?accountIdentifier=",
+			h.accountIdentifier, "&orgIdentifier=", org, "&projectIdentifier=", project, "&page=", page, "&limit=",
+			harness.PageLimit)
+		responseObj := make([]*types.RepoData, 0)
+		nextPage, err := harness.GetPage(ctx, h.httpClient, h.pat, apiURL, page, &responseObj)
+		if err != nil {
+			return repos, fmt.Errorf("error fetching harness repos for account %s , org %s & project %s: %w",
+				h.accountIdentifier, org, project, err)
+		}
+		for _, repo := range responseObj {
+			repos = append(repos, repo.Identifier)
+		}
+		page = nextPage
 	}
 	return repos, nil
 }
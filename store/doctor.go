@@ -0,0 +1,125 @@
+package store
+
+import (
+	"fmt"
+	"github.com/dhruv1397/prm/types"
+	"sort"
+	"strings"
+)
+
+// knownProviderTypes are the types of SCM providers prm can list PRs of.
+var knownProviderTypes = map[string]bool{
+	"github":          true,
+	"harness":         true,
+	"gitlab":          true,
+	"bitbucket-cloud": true,
+	"bitbucket-dc":    true,
+	"gitea":           true,
+	"azure":           true,
+	"gerrit":          true,
+}
+
+// DoctorReport lists what was fixed in the config, and the problems which need to be fixed by hand.
+type DoctorReport struct {
+	Fixed    []string
+	Problems []string
+}
+
+// checkConfig fixes what can be fixed in the config in place, ie unnamed and duplicate providers and profiles referring
+// to providers which do not exist, and reports the rest.
+func checkConfig(config *types.SCMConfig, report *DoctorReport) {
+	providersByName := map[string][]*types.SCMProvider{}
+	var names []string
+	for _, provider := range config.Providers {
+		if provider == nil {
+			continue
+		}
+		if provider.Name == "" {
+			report.Fixed = append(report.Fixed, fmt.Sprintf("removed SCM provider without a name for host %s",
+				provider.Host))
+			continue
+		}
+		if _, ok := providersByName[provider.Name]; !ok {
+			names = append(names, provider.Name)
+		}
+		providersByName[provider.Name] = append(providersByName[provider.Name], provider)
+	}
+	sort.Strings(names)
+
+	providers := make([]*types.SCMProvider, 0, len(names))
+	for _, name := range names {
+		duplicates := providersByName[name]
+		// NOTE: Keep the last duplicate in the file, which is the one the store reads as it replaces earlier ones.
+		if len(duplicates) > 1 {
+			report.Fixed = append(report.Fixed, fmt.Sprintf("removed %d earlier duplicate entries of SCM provider %s",
+				len(duplicates)-1, name))
+		}
+		provider := duplicates[len(duplicates)-1]
+		providers = append(providers, provider)
+
+		if !knownProviderTypes[provider.Type] {
+			report.Problems = append(report.Problems, fmt.Sprintf("SCM provider %s has unknown type %q, remove it "+
+				"and add it again with one of %s", name, provider.Type, strings.Join(getKnownProviderTypes(), ", ")))
+		}
+		if provider.Host == "" {
+			report.Problems = append(report.Problems, fmt.Sprintf("SCM provider %s has no host, remove it and add "+
+				"it again", name))
+		}
+		if provider.User == nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("SCM provider %s has no user, remove it and add "+
+				"it again", name))
+		} else if provider.User.PAT == "" && provider.User.PATRef == "" {
+			report.Problems = append(report.Problems, fmt.Sprintf("SCM provider %s has no PAT, remove it and add "+
+				"it again", name))
+		}
+	}
+	config.Providers = providers
+	checkProfiles(config, providersByName, report)
+}
+
+func checkProfiles(config *types.SCMConfig, providersByName map[string][]*types.SCMProvider, report *DoctorReport) {
+	profiles := make([]*types.Profile, 0, len(config.Profiles))
+	profileNames := map[string]bool{}
+	for _, profile := range config.Profiles {
+		if profile == nil {
+			continue
+		}
+		if profile.Name == "" {
+			report.Fixed = append(report.Fixed, "removed profile without a name")
+			continue
+		}
+		if profileNames[profile.Name] {
+			report.Fixed = append(report.Fixed, fmt.Sprintf("removed duplicate profile %s", profile.Name))
+			continue
+		}
+		profileNames[profile.Name] = true
+
+		providers := make([]string, 0, len(profile.Providers))
+		for _, name := range profile.Providers {
+			if len(providersByName[name]) == 0 {
+				report.Fixed = append(report.Fixed, fmt.Sprintf("removed SCM provider %s which does not exist from "+
+					"profile %s", name, profile.Name))
+				continue
+			}
+			providers = append(providers, name)
+		}
+		profile.Providers = providers
+		profiles = append(profiles, profile)
+	}
+	config.Profiles = profiles
+
+	if config.CurrentProfile != "" && !profileNames[config.CurrentProfile] {
+		report.Fixed = append(report.Fixed, fmt.Sprintf("stopped using profile %s which does not exist",
+			config.CurrentProfile))
+		config.CurrentProfile = ""
+	}
+}
+
+func getKnownProviderTypes() []string {
+	providerTypes := make([]string, 0, len(knownProviderTypes))
+	for providerType := range knownProviderTypes {
+		providerTypes = append(providerTypes, providerType)
+	}
+	sort.Strings(providerTypes)
+	return providerTypes
+}
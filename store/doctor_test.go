@@ -0,0 +1,26 @@
+package store
+
+import (
+	"github.com/dhruv1397/prm/types"
+	"reflect"
+	"testing"
+)
+
+func TestCheckConfigKeepsLastDuplicate(t *testing.T) {
+	config := &types.SCMConfig{
+		Providers: []*types.SCMProvider{
+			{Type: "github", Name: "gh", Host: "https://first.example.com", Updated: 2},
+			{Type: "github", Name: "gh", Host: "https://last.example.com", Updated: 1},
+		},
+	}
+	report := &DoctorReport{}
+	checkConfig(config, report)
+
+	if len(config.Providers) != 1 || config.Providers[0].Host != "https://last.example.com" {
+		t.Errorf("checkConfig kept %+v, want the last duplicate in the file", config.Providers)
+	}
+	want := []string{"removed 1 earlier duplicate entries of SCM provider gh"}
+	if !reflect.DeepEqual(report.Fixed, want) {
+		t.Errorf("checkConfig fixed %q, want %q", report.Fixed, want)
+	}
+}
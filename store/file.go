@@ -0,0 +1,132 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/types"
+	"github.com/dhruv1397/prm/util"
+	"gopkg.in/yaml.v3"
+	"os"
+	"time"
+)
+
+const (
+	lockFileSuffix    = ".lock"
+	backupFileSuffix  = ".bak"
+	corruptFileSuffix = ".corrupt"
+
+	lockTimeout       = 30 * time.Second
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// errLocked is returned by tryLockFile when another process holds the lock.
+var errLocked = errors.New("file is locked")
+
+// lockConfig takes an exclusive lock on the config file, waiting for other prm processes to release theirs, eg a refresh
+// run from cron. Every read-modify-write of the config has to happen while holding it. The lock is not re-entrant.
+func lockConfig() (func(), error) {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("error getting SCM provider config file path before locking: %w", err)
+	}
+	return lockFile(configFilePath + lockFileSuffix)
+}
+
+// lockFile takes an exclusive lock on the lock file, see lockConfig.
+func lockFile(lockFilePath string) (func(), error) {
+	file, err := os.OpenFile(lockFilePath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening SCM provider config lock file %s: %w", lockFilePath, err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err = tryLockFile(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			file.Close()
+			if errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("SCM provider config is locked by another prm process, remove %s if there is none",
+					lockFilePath)
+			}
+			return nil, fmt.Errorf("error locking SCM provider config: %w", err)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	return func() {
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// readConfigFile returns the content of the config file, nil if there is none yet. A corrupt config is replaced by its
+// backup, and kept aside for inspection.
+func readConfigFile(configFilePath string) ([]byte, error) {
+	content, err := os.ReadFile(configFilePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading SCM provider config file %s: %w", configFilePath, err)
+	}
+	if isValidConfig(content) {
+		return content, nil
+	}
+
+	backupFilePath := configFilePath + backupFileSuffix
+	backup, err := os.ReadFile(backupFilePath)
+	if err != nil || !isValidConfig(backup) {
+		return nil, fmt.Errorf("SCM provider config file %s is corrupt and there is no valid backup at %s",
+			configFilePath, backupFilePath)
+	}
+
+	corruptFilePath := configFilePath + corruptFileSuffix
+	err = os.Rename(configFilePath, corruptFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error moving corrupt SCM provider config file aside: %w", err)
+	}
+	err = util.WriteFileAtomic(configFilePath, backup)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring SCM provider config file from backup: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "SCM provider config file %s was corrupt, restored it from %s and moved it to %s\n",
+		configFilePath, backupFilePath, corruptFilePath)
+	return backup, nil
+}
+
+// writeConfigFile replaces the config file atomically, keeping the previous config as a backup.
+func writeConfigFile(configFilePath string, data []byte) error {
+	current, err := os.ReadFile(configFilePath)
+	if err == nil && len(current) > 0 && isValidConfig(current) {
+		err = util.WriteFileAtomic(configFilePath+backupFileSuffix, current)
+		if err != nil {
+			return fmt.Errorf("error backing up SCM provider config: %w", err)
+		}
+	}
+	return util.WriteFileAtomic(configFilePath, data)
+}
+
+// removeConfigBackup removes the backup of the config, eg when it holds secrets which have been moved elsewhere.
+func removeConfigBackup() error {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return fmt.Errorf("error getting SCM provider config file path: %w", err)
+	}
+	err = os.Remove(configFilePath + backupFileSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isValidConfig reports whether the content is a config that can be read, either encrypted or in plaintext.
+func isValidConfig(content []byte) bool {
+	if len(content) == 0 || encryption.IsEncrypted(content) {
+		return true
+	}
+	return yaml.Unmarshal(content, &types.SCMConfig{}) == nil
+}
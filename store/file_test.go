@@ -0,0 +1,149 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	validConfig   = "version: 1\nproviders:\n- type: github\n  name: gh\n"
+	corruptConfig = "providers: [\n"
+)
+
+func TestLockFile(t *testing.T) {
+	lockFilePath := filepath.Join(t.TempDir(), "config.yaml"+lockFileSuffix)
+	unlock, err := lockFile(lockFilePath)
+	if err != nil {
+		t.Fatalf("lockFile returned error: %v", err)
+	}
+
+	file, err := os.OpenFile(lockFilePath, os.O_RDWR, 0600)
+	if err != nil {
+		t.Fatalf("error opening lock file: %v", err)
+	}
+	defer file.Close()
+	if err := tryLockFile(file); !errors.Is(err, errLocked) {
+		t.Fatalf("tryLockFile while the lock is held returned %v, want errLocked", err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		unlockSecond, err := lockFile(lockFilePath)
+		if err == nil {
+			unlockSecond()
+		}
+		locked <- err
+	}()
+	select {
+	case err := <-locked:
+		t.Fatalf("second lockFile returned %v while the lock is held, want it to wait", err)
+	case <-time.After(2 * lockRetryInterval):
+	}
+
+	unlock()
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatalf("second lockFile returned error: %v", err)
+		}
+	case <-time.After(10 * lockRetryInterval):
+		t.Fatal("second lockFile did not get the lock once it was released")
+	}
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	configFilePath := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := writeConfigFile(configFilePath, []byte(validConfig)); err != nil {
+		t.Fatalf("first writeConfigFile returned error: %v", err)
+	}
+	if _, err := os.Stat(configFilePath + backupFileSuffix); !os.IsNotExist(err) {
+		t.Errorf("backup exists after the first write, want none as there was no config to back up")
+	}
+
+	updated := validConfig + "- type: gitlab\n  name: gl\n"
+	if err := writeConfigFile(configFilePath, []byte(updated)); err != nil {
+		t.Fatalf("second writeConfigFile returned error: %v", err)
+	}
+	assertFileContent(t, configFilePath, updated)
+	assertFileContent(t, configFilePath+backupFileSuffix, validConfig)
+}
+
+func TestWriteConfigFileKeepsValidBackup(t *testing.T) {
+	configFilePath := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, configFilePath, corruptConfig)
+	writeFile(t, configFilePath+backupFileSuffix, validConfig)
+
+	if err := writeConfigFile(configFilePath, []byte(validConfig)); err != nil {
+		t.Fatalf("writeConfigFile returned error: %v", err)
+	}
+	assertFileContent(t, configFilePath+backupFileSuffix, validConfig)
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *string
+		backup      *string
+		want        string
+		wantErr     bool
+		wantCorrupt bool
+	}{
+		{"missing", nil, nil, "", false, false},
+		{"valid", ptr(validConfig), nil, validConfig, false, false},
+		{"empty", ptr(""), nil, "", false, false},
+		{"corrupt with a backup", ptr(corruptConfig), ptr(validConfig), validConfig, false, true},
+		{"corrupt without a backup", ptr(corruptConfig), nil, "", true, false},
+		{"corrupt with a corrupt backup", ptr(corruptConfig), ptr(corruptConfig), "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFilePath := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.config != nil {
+				writeFile(t, configFilePath, *tt.config)
+			}
+			if tt.backup != nil {
+				writeFile(t, configFilePath+backupFileSuffix, *tt.backup)
+			}
+
+			content, err := readConfigFile(configFilePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readConfigFile returned error %v, want error %v", err, tt.wantErr)
+			}
+			if string(content) != tt.want {
+				t.Errorf("readConfigFile returned %q, want %q", content, tt.want)
+			}
+			if tt.wantCorrupt {
+				assertFileContent(t, configFilePath, validConfig)
+				assertFileContent(t, configFilePath+corruptFileSuffix, corruptConfig)
+			} else if _, err := os.Stat(configFilePath + corruptFileSuffix); !os.IsNotExist(err) {
+				t.Errorf("config was moved aside, want it left in place")
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("error writing %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading %s: %v", path, err)
+	}
+	if string(content) != want {
+		t.Errorf("%s holds %q, want %q", filepath.Base(path), content, want)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
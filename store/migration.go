@@ -0,0 +1,48 @@
+package store
+
+import (
+	"fmt"
+	"github.com/dhruv1397/prm/types"
+)
+
+// configVersion is the version of the config schema written by this version of prm. Bump it with every change to the
+// schema that older configs have to be upgraded for, and add the migration doing so.
+const configVersion = 1
+
+// migration upgrades a config by one version in place and describes each change it made.
+type migration func(config *types.SCMConfig) []string
+
+// migrations upgrade a config step by step, migrations[i] from version i to version i+1.
+var migrations = []migration{
+	migrateToV1,
+}
+
+// migrateConfig upgrades the config to the current version and describes each change made on the way.
+func migrateConfig(config *types.SCMConfig) ([]string, error) {
+	if config.Version > configVersion {
+		return nil, fmt.Errorf("SCM provider config version %d is newer than version %d supported by this prm, "+
+			"upgrade prm to use it", config.Version, configVersion)
+	}
+
+	var changes []string
+	for config.Version < configVersion {
+		changes = append(changes, migrations[config.Version](config)...)
+		config.Version++
+	}
+	return changes, nil
+}
+
+// migrateToV1 drops empty provider entries.
+func migrateToV1(config *types.SCMConfig) []string {
+	var changes []string
+	providers := make([]*types.SCMProvider, 0, len(config.Providers))
+	for _, provider := range config.Providers {
+		if provider == nil {
+			changes = append(changes, "removed an empty SCM provider entry")
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	config.Providers = providers
+	return changes
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"github.com/dhruv1397/prm/types"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantProviders []string
+		wantChanges   []string
+	}{
+		{
+			"unversioned config",
+			"scm_providers:\n- type: github\n  name: gh\n",
+			[]string{"gh"},
+			nil,
+		},
+		{
+			"unversioned config with empty entries",
+			"scm_providers:\n-\n- type: github\n  name: gh\n- ~\n",
+			[]string{"gh"},
+			[]string{"removed an empty SCM provider entry", "removed an empty SCM provider entry"},
+		},
+		{
+			"current config",
+			"version: 1\nscm_providers:\n- type: gitlab\n  name: gl\n",
+			[]string{"gl"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &types.SCMConfig{}
+			if err := yaml.Unmarshal([]byte(tt.content), config); err != nil {
+				t.Fatalf("error parsing config: %v", err)
+			}
+
+			changes, err := migrateConfig(config)
+			if err != nil {
+				t.Fatalf("migrateConfig returned error: %v", err)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("migrateConfig made changes %q, want %q", changes, tt.wantChanges)
+			}
+			if config.Version != configVersion {
+				t.Errorf("config is at version %d, want %d", config.Version, configVersion)
+			}
+			var names []string
+			for _, provider := range config.Providers {
+				names = append(names, provider.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantProviders) {
+				t.Errorf("config has providers %q, want %q", names, tt.wantProviders)
+			}
+		})
+	}
+}
+
+func TestMigrateConfigRejectsNewerVersion(t *testing.T) {
+	config := &types.SCMConfig{Version: configVersion + 1}
+	if _, err := migrateConfig(config); err == nil {
+		t.Fatal("migrateConfig of a newer config returned no error")
+	}
+	if config.Version != configVersion+1 {
+		t.Errorf("config is at version %d, want it left at %d", config.Version, configVersion+1)
+	}
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	if len(migrations) != configVersion {
+		t.Errorf("there are %d migrations, want one per version up to %d", len(migrations), configVersion)
+	}
+}
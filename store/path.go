@@ -0,0 +1,166 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dhruv1397/prm/keyring"
+	"github.com/dhruv1397/prm/util"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+const (
+	configFileName       = "config.yaml"
+	keyringFileSuffix    = ".keyring"
+	legacyConfigFileName = ".prm_config"
+	legacyKeyringName    = ".prm_keyring"
+	defaultKeyringName   = "keyring"
+)
+
+var (
+	configFilePathOverride string
+
+	configFilePath     string
+	configFilePathErr  error
+	configFilePathOnce sync.Once
+)
+
+// SetConfigFilePath makes the store use the config at the path instead of the one in the prm config directory, eg to
+// keep separate work and personal configs. It has to be called before the store is used.
+func SetConfigFilePath(path string) {
+	configFilePathOverride = path
+}
+
+// GetConfigFilePath returns the path of the config used by the store.
+func GetConfigFilePath() (string, error) {
+	return getConfigFilePath()
+}
+
+// getConfigFilePath resolves the path of the config once per process. The file keyring is kept next to the config.
+func getConfigFilePath() (string, error) {
+	configFilePathOnce.Do(func() {
+		if configFilePathOverride != "" {
+			configFilePath, configFilePathErr = resolveConfigFilePathOverride(configFilePathOverride)
+		} else {
+			configFilePath, configFilePathErr = resolveDefaultConfigFilePath()
+		}
+	})
+	return configFilePath, configFilePathErr
+}
+
+func resolveConfigFilePathOverride(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("error resolving SCM provider config file path %s: %w", path, err)
+	}
+	err = os.MkdirAll(filepath.Dir(absPath), 0700)
+	if err != nil {
+		return "", fmt.Errorf("error creating directory of SCM provider config file %s: %w", absPath, err)
+	}
+	keyring.SetFilePath(absPath + keyringFileSuffix)
+	return absPath, nil
+}
+
+func resolveDefaultConfigFilePath() (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(configDir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("error creating prm config directory %s: %w", configDir, err)
+	}
+
+	path := filepath.Join(configDir, configFileName)
+	keyringPath := filepath.Join(configDir, defaultKeyringName)
+	err = migrateLegacyFiles(path, keyringPath)
+	if err != nil {
+		return "", err
+	}
+	keyring.SetFilePath(keyringPath)
+	return path, nil
+}
+
+// migrateLegacyFiles moves the config and file keyring of older versions of prm, which were kept directly in the home
+// directory, to the prm config directory. The files are moved while holding the lock of the config, so that prm
+// processes started at once do not race on them.
+func migrateLegacyFiles(path string, keyringPath string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("error getting user home directory: %w", err)
+	}
+	legacyPath := filepath.Join(homeDir, legacyConfigFileName)
+	if !hasLegacyConfig(path, legacyPath) {
+		return nil
+	}
+
+	unlock, err := lockFile(path + lockFileSuffix)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// NOTE: Another prm process may have moved the files while this one waited for the lock.
+	if !hasLegacyConfig(path, legacyPath) {
+		return nil
+	}
+
+	moves := [][2]string{
+		{legacyPath + backupFileSuffix, path + backupFileSuffix},
+		{filepath.Join(homeDir, legacyKeyringName), keyringPath},
+		// NOTE: The config goes last so that the migration is retried should moving anything else fail.
+		{legacyPath, path},
+	}
+	for _, move := range moves {
+		if _, err := os.Stat(move[1]); err == nil {
+			continue
+		}
+		err = moveFile(move[0], move[1])
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error moving %s to %s: %w", move[0], move[1], err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Moved the SCM provider config from %s to %s\n", legacyPath, path)
+	return nil
+}
+
+// hasLegacyConfig reports whether there is a config of an older version of prm to move to the path.
+func hasLegacyConfig(path string, legacyPath string) bool {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return false
+	}
+	_, err := os.Stat(legacyPath)
+	return !os.IsNotExist(err)
+}
+
+// moveFile renames the file, or copies and removes it when the target is on another filesystem, eg when
+// $XDG_CONFIG_HOME is mounted apart from the home directory.
+func moveFile(from string, to string) error {
+	err := os.Rename(from, to)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	err = util.WriteFileAtomic(to, data)
+	if err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// getKeyringKey returns the key under which the PAT of the provider is kept in the keyring. Configs given with
+// --config get their own keys, so that providers with the same name in different configs do not overwrite each other.
+func getKeyringKey(providerName string) (string, error) {
+	if configFilePathOverride == "" {
+		return providerName, nil
+	}
+	path, err := getConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return providerName + "@" + path, nil
+}
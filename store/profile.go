@@ -0,0 +1,110 @@
+package store
+
+import (
+	"fmt"
+	"github.com/dhruv1397/prm/types"
+	"slices"
+)
+
+var profileOverride string
+
+// SetProfile makes the store act on the providers of the profile instead of those of the profile in use, eg for a
+// single command. It has to be called before the store is used.
+func SetProfile(name string) {
+	profileOverride = name
+}
+
+// getActiveProfile returns the profile the store acts on, nil when it acts on all the providers.
+func getActiveProfile(config *types.SCMConfig) (*types.Profile, error) {
+	name := config.CurrentProfile
+	if profileOverride != "" {
+		name = profileOverride
+	}
+	if name == "" {
+		return nil, nil
+	}
+	profile := findProfile(config, name)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s does not exist", name)
+	}
+	return profile, nil
+}
+
+func findProfile(config *types.SCMConfig, name string) *types.Profile {
+	for _, profile := range config.Profiles {
+		if profile != nil && profile.Name == name {
+			return profile
+		}
+	}
+	return nil
+}
+
+func addProfile(config *types.SCMConfig, existingProviders map[string]*types.SCMProvider, profile types.Profile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("profile name must not be empty")
+	}
+	if findProfile(config, profile.Name) != nil {
+		return fmt.Errorf("profile %s already exists", profile.Name)
+	}
+
+	providers := make([]string, 0, len(profile.Providers))
+	for _, name := range profile.Providers {
+		if existingProviders[name] == nil {
+			return fmt.Errorf("SCM provider %s does not exist", name)
+		}
+		if !slices.Contains(providers, name) {
+			providers = append(providers, name)
+		}
+	}
+	profile.Providers = providers
+
+	config.Profiles = append(config.Profiles, &profile)
+	return nil
+}
+
+func useProfile(config *types.SCMConfig, name string) error {
+	if name != "" && findProfile(config, name) == nil {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	config.CurrentProfile = name
+	return nil
+}
+
+func deleteProfile(config *types.SCMConfig, name string) error {
+	if findProfile(config, name) == nil {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	config.Profiles = slices.DeleteFunc(config.Profiles, func(profile *types.Profile) bool {
+		return profile == nil || profile.Name == name
+	})
+	if config.CurrentProfile == name {
+		config.CurrentProfile = ""
+	}
+	return nil
+}
+
+// removeFromProfiles drops the provider from every profile, the profiles themselves are kept even when left empty.
+func removeFromProfiles(config *types.SCMConfig, providerName string) {
+	for _, profile := range config.Profiles {
+		if profile != nil {
+			profile.Providers = slices.DeleteFunc(profile.Providers, func(name string) bool {
+				return name == providerName
+			})
+		}
+	}
+}
+
+// getProfiles returns the profiles of the config and the name of the one in use.
+func getProfiles(config *types.SCMConfig) ([]*types.Profile, string) {
+	profiles := make([]*types.Profile, 0, len(config.Profiles))
+	for _, profile := range config.Profiles {
+		if profile != nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	current := config.CurrentProfile
+	if profileOverride != "" {
+		current = profileOverride
+	}
+	return profiles, current
+}
@@ -8,6 +8,18 @@ type SCMProvider interface {
 	Create(provider types.SCMProvider) error
 	UpdateBulk(providers []types.SCMProvider) error
 	List(providerType string, providerName string) ([]*types.SCMProvider, error)
+	// ListAll returns every provider sorted by name, the profiles and the name of the profile set with `prm profile
+	// use`, whichever profile the store acts on, eg to export the whole config.
+	ListAll() ([]*types.SCMProvider, []*types.Profile, string, error)
 	Delete(name string) error
 	Purge() error
+	// Doctor fixes what it can in the config and reports the problems left.
+	Doctor() (*DoctorReport, error)
+	// CreateProfile creates a profile grouping existing providers.
+	CreateProfile(profile types.Profile) error
+	// UseProfile makes the store act on the providers of the profile, or on all the providers when the name is empty.
+	UseProfile(name string) error
+	DeleteProfile(name string) error
+	// ListProfiles returns the profiles and the name of the one in use.
+	ListProfiles() ([]*types.Profile, string, error)
 }
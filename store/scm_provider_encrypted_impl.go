@@ -0,0 +1,504 @@
+package store
+
+import (
+	"fmt"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/keyring"
+	"github.com/dhruv1397/prm/types"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+var _ SCMProvider = (*scmProviderEncryptedImpl)(nil)
+
+// PassphraseEnv is the environment variable holding the passphrase of an encrypted config, prompted for otherwise.
+const PassphraseEnv = "PRM_CONFIG_PASSPHRASE"
+
+// scmProviderEncryptedImpl keeps the whole config, PATs included, encrypted with a passphrase in the config file. It is
+// meant for machines without an OS keyring, eg shared build boxes.
+type scmProviderEncryptedImpl struct {
+	passphrase string
+}
+
+func NewSCMProviderEncryptedImpl() SCMProvider {
+	return &scmProviderEncryptedImpl{}
+}
+
+// NewSCMProvider returns the store matching the config file on disk, ie the encrypted one once the config has been
+// encrypted with `prm config encrypt` and the plaintext one otherwise.
+func NewSCMProvider() SCMProvider {
+	encrypted, err := IsConfigEncrypted()
+	if err == nil && encrypted {
+		return NewSCMProviderEncryptedImpl()
+	}
+	// NOTE: Errors resurface with more context when the plaintext store reads the config.
+	return NewSCMProviderImpl()
+}
+
+// IsConfigEncrypted reports whether the config file has been encrypted with a passphrase.
+func IsConfigEncrypted() (bool, error) {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return false, fmt.Errorf("error getting SCM provider config file path: %w", err)
+	}
+	content, err := os.ReadFile(configFilePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error reading SCM provider config file %s: %w", configFilePath, err)
+	}
+	// NOTE: A corrupt config is replaced by its backup once read, which tells what kind of config it is.
+	if !isValidConfig(content) {
+		backup, err := os.ReadFile(configFilePath + backupFileSuffix)
+		if err == nil {
+			content = backup
+		}
+	}
+	return encryption.IsEncrypted(content), nil
+}
+
+// EncryptConfig converts the plaintext config into an encrypted one. The PATs are moved from the keyring into the
+// encrypted config.
+func EncryptConfig() error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	encrypted, err := IsConfigEncrypted()
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return fmt.Errorf("SCM provider config is already encrypted")
+	}
+
+	plaintextStore := &scmProviderImpl{}
+	plaintextConfig, providerMap, err := plaintextStore.readYAML()
+	if err != nil {
+		return fmt.Errorf("error reading SCM provider config before encrypting: %w", err)
+	}
+	// NOTE: Read after readYAML, which may have just moved plaintext PATs to the keyring.
+	config, err := plaintextStore.readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading SCM provider config before encrypting: %w", err)
+	}
+
+	encryptedStore := &scmProviderEncryptedImpl{}
+	err = encryptedStore.write(plaintextConfig, providerMap, true)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: The config is only readable with the passphrase from here on, a failure to clean the keyring up merely
+	// leaves stale copies of the PATs behind.
+	kr, err := keyring.Default()
+	if err != nil {
+		return fmt.Errorf("error opening keyring to delete the PATs moved to the encrypted config: %w", err)
+	}
+	for _, provider := range config.Providers {
+		if provider != nil && provider.User != nil && strings.HasPrefix(provider.User.PATRef, keyringRefPrefix) {
+			err = kr.Delete(strings.TrimPrefix(provider.User.PATRef, keyringRefPrefix))
+			if err != nil {
+				return fmt.Errorf("error deleting PAT of SCM provider %s moved to the encrypted config: %w",
+					provider.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DecryptConfig converts the encrypted config back into a plaintext one. The PATs are moved to the keyring.
+func DecryptConfig() error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	encrypted, err := IsConfigEncrypted()
+	if err != nil {
+		return err
+	}
+	if !encrypted {
+		return fmt.Errorf("SCM provider config is not encrypted")
+	}
+
+	encryptedStore := &scmProviderEncryptedImpl{}
+	config, providerMap, err := encryptedStore.read()
+	if err != nil {
+		return err
+	}
+
+	plaintextStore := &scmProviderImpl{}
+	err = plaintextStore.writeYAML(config, providerMap)
+	if err != nil {
+		return fmt.Errorf("error writing decrypted SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) Create(provider types.SCMProvider) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.read()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before creating new: %w", err)
+	}
+
+	err = addProvider(config, existingProviders, provider)
+	if err != nil {
+		return err
+	}
+
+	err = s.write(config, existingProviders, false)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) UpdateBulk(providers []types.SCMProvider) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.read()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before updating: %w", err)
+	}
+
+	err = updateProviders(existingProviders, providers)
+	if err != nil {
+		return err
+	}
+
+	err = s.write(config, existingProviders, false)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) List(providerType string, providerName string) ([]*types.SCMProvider, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, providerMap, err := s.read()
+	if err != nil {
+		return nil, fmt.Errorf("error listing SCM providers: %w", err)
+	}
+	return filterProviders(config, providerMap, providerType, providerName)
+}
+
+func (s *scmProviderEncryptedImpl) ListAll() ([]*types.SCMProvider, []*types.Profile, string, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer unlock()
+
+	config, providerMap, err := s.read()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("error listing SCM providers: %w", err)
+	}
+	providers, profiles, current := listAll(config, providerMap)
+	return providers, profiles, current, nil
+}
+
+func (s *scmProviderEncryptedImpl) Delete(name string) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.read()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before deleting: %w", err)
+	}
+
+	exists, err := isProviderInScope(config, existingProviders, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Printf("SCM provider %s does not exist\n", name)
+		return nil
+	}
+
+	existingProviders[name] = nil
+	removeFromProfiles(config, name)
+
+	err = s.write(config, existingProviders, false)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) Purge() error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = (&scmProviderImpl{}).deleteYAML()
+	if err != nil {
+		return err
+	}
+
+	// NOTE: The keyring file may be left behind from before the config was encrypted.
+	err = keyring.Purge(nil)
+	if err != nil {
+		return fmt.Errorf("error purging SCM provider PATs: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) Doctor() (*DoctorReport, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, changes, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DoctorReport{Fixed: changes}
+	checkConfig(config, report)
+	if len(report.Fixed) > len(changes) {
+		err = s.writeConfig(config, false)
+		if err != nil {
+			return nil, fmt.Errorf("error writing fixed SCM provider config: %w", err)
+		}
+	}
+	return report, nil
+}
+
+func (s *scmProviderEncryptedImpl) CreateProfile(profile types.Profile) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.read()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before creating profile: %w", err)
+	}
+
+	err = addProfile(config, existingProviders, profile)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeConfig(config, false)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) UseProfile(name string) error {
+	return s.updateProfiles(func(config *types.SCMConfig) error {
+		return useProfile(config, name)
+	})
+}
+
+func (s *scmProviderEncryptedImpl) DeleteProfile(name string) error {
+	return s.updateProfiles(func(config *types.SCMConfig) error {
+		return deleteProfile(config, name)
+	})
+}
+
+func (s *scmProviderEncryptedImpl) ListProfiles() ([]*types.Profile, string, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	config, _, err := s.loadConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing profiles: %w", err)
+	}
+	profiles, current := getProfiles(config)
+	return profiles, current, nil
+}
+
+// updateProfiles applies the update to the profiles of the config, the providers are written back as they are.
+func (s *scmProviderEncryptedImpl) updateProfiles(update func(config *types.SCMConfig) error) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, _, err := s.loadConfig()
+	if err != nil {
+		return fmt.Errorf("error reading SCM provider config before updating profiles: %w", err)
+	}
+
+	err = update(config)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeConfig(config, false)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+// read decrypts the config along with its providers by name.
+func (s *scmProviderEncryptedImpl) read() (*types.SCMConfig, map[string]*types.SCMProvider, error) {
+	config, _, err := s.loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providerMap := map[string]*types.SCMProvider{}
+	for _, provider := range config.Providers {
+		if provider != nil {
+			providerMap[provider.Name] = provider
+		}
+	}
+	return config, providerMap, nil
+}
+
+// loadConfig decrypts the config file and upgrades it to the current version, describing what the upgrade changed.
+func (s *scmProviderEncryptedImpl) loadConfig() (*types.SCMConfig, []string, error) {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting SCM provider config file path before reading: %w", err)
+	}
+
+	content, err := readConfigFile(configFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !encryption.IsEncrypted(content) {
+		return nil, nil, fmt.Errorf("SCM provider config file %s is not encrypted", configFilePath)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := encryption.Decrypt(content, passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decrypting SCM provider config file %s: %w", configFilePath, err)
+	}
+
+	var config = &types.SCMConfig{}
+	err = yaml.Unmarshal(plaintext, &config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error deserialising SCM provider config: %w", err)
+	}
+	if config == nil {
+		config = &types.SCMConfig{}
+	}
+
+	version := config.Version
+	changes, err := migrateConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.Version != version {
+		err = s.writeConfig(config, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error writing upgraded SCM provider config: %w", err)
+		}
+	}
+
+	return config, changes, nil
+}
+
+// write encrypts the providers into the config file, keeping the rest of the config, eg the profiles, as it is. With
+// newPassphrase set the passphrase is asked to be confirmed, unless it has already been read.
+func (s *scmProviderEncryptedImpl) write(config *types.SCMConfig, providerMap map[string]*types.SCMProvider,
+	newPassphrase bool) error {
+	providers := make([]*types.SCMProvider, 0)
+	for name, provider := range providerMap {
+		if name == "" || provider == nil {
+			continue
+		}
+		// NOTE: PATs read from the keyring are kept in the encrypted config itself, the keyring is not used by it.
+		if provider.User != nil && strings.HasPrefix(provider.User.PATRef, keyringRefPrefix) {
+			storedUser := *provider.User
+			storedUser.PATRef = ""
+			storedProvider := *provider
+			storedProvider.User = &storedUser
+			provider = &storedProvider
+		}
+		providers = append(providers, provider)
+	}
+	return s.writeConfig(&types.SCMConfig{
+		Providers:      providers,
+		Profiles:       config.Profiles,
+		CurrentProfile: config.CurrentProfile,
+	}, newPassphrase)
+}
+
+// writeConfig encrypts the config as it is, at the current version, into the config file.
+func (s *scmProviderEncryptedImpl) writeConfig(config *types.SCMConfig, newPassphrase bool) error {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return fmt.Errorf("error getting SCM provider config file path before writing: %w", err)
+	}
+
+	config.Version = configVersion
+	yamlData, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error serialising SCM provider config: %w", err)
+	}
+
+	passphrase, err := s.getPassphrase(newPassphrase)
+	if err != nil {
+		return err
+	}
+	data, err := encryption.Encrypt(yamlData, passphrase)
+	if err != nil {
+		return fmt.Errorf("error encrypting SCM provider config: %w", err)
+	}
+
+	err = writeConfigFile(configFilePath, data)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderEncryptedImpl) getPassphrase(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	prompt := "Enter the passphrase of the prm config:"
+	if confirm {
+		prompt = "Enter a passphrase to encrypt the prm config with:"
+	}
+	passphrase, err := encryption.ReadPassphrase(PassphraseEnv, prompt, confirm)
+	if err != nil {
+		return "", err
+	}
+	s.passphrase = passphrase
+	return passphrase, nil
+}
@@ -1,19 +1,30 @@
 package store
 
 import (
+	"errors"
 	"fmt"
+	"github.com/dhruv1397/prm/encryption"
+	"github.com/dhruv1397/prm/keyring"
 	"github.com/dhruv1397/prm/types"
 	"gopkg.in/yaml.v3"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
 var _ SCMProvider = (*scmProviderImpl)(nil)
 
-const configFileName = ".prm_config"
+const (
+	// keyringRefPrefix marks PATs kept in the keyring under the name of their SCM provider.
+	keyringRefPrefix = "keyring:"
+)
 
 type scmProviderImpl struct {
+	// keyringPATs are the PATs read from the keyring by key, which spares writing back the ones that did not change.
+	keyringPATs map[string]string
+	mu          sync.Mutex
 }
 
 func NewSCMProviderImpl() SCMProvider {
@@ -21,21 +32,23 @@ func NewSCMProviderImpl() SCMProvider {
 }
 
 func (s *scmProviderImpl) Create(provider types.SCMProvider) error {
-	existingProviders, err := s.readYAML()
+	unlock, err := lockConfig()
 	if err != nil {
-		return fmt.Errorf("error listing existing SCM providers before creating new: %w", err)
+		return err
 	}
+	defer unlock()
 
-	if existingProviders[provider.Name] != nil {
-		return fmt.Errorf("SCM provider %s already exists", provider.Name)
+	config, existingProviders, err := s.readYAML()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before creating new: %w", err)
 	}
 
-	provider.Updated = time.Now().UnixMilli()
-	provider.Created = time.Now().UnixMilli()
-
-	existingProviders[provider.Name] = &provider
+	err = addProvider(config, existingProviders, provider)
+	if err != nil {
+		return err
+	}
 
-	err = s.writeYAML(existingProviders)
+	err = s.writeYAML(config, existingProviders)
 	if err != nil {
 		return fmt.Errorf("error writing SCM provider config: %w", err)
 	}
@@ -43,20 +56,23 @@ func (s *scmProviderImpl) Create(provider types.SCMProvider) error {
 }
 
 func (s *scmProviderImpl) UpdateBulk(providers []types.SCMProvider) error {
-	existingProviders, err := s.readYAML()
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.readYAML()
 	if err != nil {
 		return fmt.Errorf("error listing existing SCM providers before updating: %w", err)
 	}
 
-	for _, provider := range providers {
-		if existingProviders[provider.Name] == nil {
-			return fmt.Errorf("SCM provider %s does not exist", provider.Name)
-		}
-		provider.Updated = time.Now().UnixMilli()
-		existingProviders[provider.Name] = &provider
+	err = updateProviders(existingProviders, providers)
+	if err != nil {
+		return err
 	}
 
-	err = s.writeYAML(existingProviders)
+	err = s.writeYAML(config, existingProviders)
 	if err != nil {
 		return fmt.Errorf("error writing SCM provider config: %w", err)
 	}
@@ -64,110 +80,386 @@ func (s *scmProviderImpl) UpdateBulk(providers []types.SCMProvider) error {
 }
 
 func (s *scmProviderImpl) List(providerType string, providerName string) ([]*types.SCMProvider, error) {
-	providerMap, err := s.readYAML()
+	unlock, err := lockConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error listing SCM providers: %w", err)
+		return nil, err
 	}
-	if providerMap == nil {
-		return []*types.SCMProvider{}, nil
+	defer unlock()
+
+	config, providerMap, err := s.readYAML()
+	if err != nil {
+		return nil, fmt.Errorf("error listing SCM providers: %w", err)
 	}
-	providers := make([]*types.SCMProvider, 0)
-	for name, provider := range providerMap {
-		if name != "" && provider != nil &&
-			(providerName == "" || providerName == provider.Name) &&
-			(providerType == "" || providerType == provider.Type) {
-			providers = append(providers, provider)
-		}
+	return filterProviders(config, providerMap, providerType, providerName)
+}
+
+func (s *scmProviderImpl) ListAll() ([]*types.SCMProvider, []*types.Profile, string, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, nil, "", err
 	}
+	defer unlock()
 
-	return providers, nil
+	config, providerMap, err := s.readYAML()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("error listing SCM providers: %w", err)
+	}
+	providers, profiles, current := listAll(config, providerMap)
+	return providers, profiles, current, nil
 }
 
 func (s *scmProviderImpl) Delete(name string) error {
-	existingProviders, err := s.readYAML()
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, existingProviders, err := s.readYAML()
 	if err != nil {
 		return fmt.Errorf("error listing existing SCM providers before deleting: %w", err)
 	}
 
-	if existingProviders[name] == nil {
+	exists, err := isProviderInScope(config, existingProviders, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
 		fmt.Printf("SCM provider %s does not exist\n", name)
 		return nil
 	}
 
+	user := existingProviders[name].User
 	existingProviders[name] = nil
+	removeFromProfiles(config, name)
 
-	err = s.writeYAML(existingProviders)
+	err = s.writeYAML(config, existingProviders)
 	if err != nil {
 		return fmt.Errorf("error writing SCM provider config: %w", err)
 	}
+
+	if user != nil && strings.HasPrefix(user.PATRef, keyringRefPrefix) {
+		kr, err := keyring.Default()
+		if err != nil {
+			return fmt.Errorf("error opening keyring to delete PAT of SCM provider %s: %w", name, err)
+		}
+		err = kr.Delete(strings.TrimPrefix(user.PATRef, keyringRefPrefix))
+		if err != nil {
+			return fmt.Errorf("error deleting PAT of SCM provider %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
 func (s *scmProviderImpl) Purge() error {
-	return s.deleteYAML()
-}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-func (s *scmProviderImpl) getConfigFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	config, err := s.readConfig()
 	if err != nil {
-		return "", fmt.Errorf("error getting user home directory: %w", err)
+		return fmt.Errorf("error listing existing SCM providers before purging: %w", err)
 	}
-	configFilePath := filepath.Join(homeDir, configFileName)
-	return configFilePath, nil
+
+	var keys []string
+	for _, provider := range config.Providers {
+		if provider != nil && provider.User != nil && strings.HasPrefix(provider.User.PATRef, keyringRefPrefix) {
+			keys = append(keys, strings.TrimPrefix(provider.User.PATRef, keyringRefPrefix))
+		}
+	}
+
+	err = s.deleteYAML()
+	if err != nil {
+		return err
+	}
+
+	err = keyring.Purge(keys)
+	if err != nil {
+		return fmt.Errorf("error purging SCM provider PATs: %w", err)
+	}
+	return nil
 }
 
-func (s *scmProviderImpl) readYAML() (map[string]*types.SCMProvider, error) {
-	configFilePath, err := s.getConfigFilePath()
+func (s *scmProviderImpl) Doctor() (*DoctorReport, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, changes, err := s.loadConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error getting SCM provider config file path before reading: %w", err)
+		return nil, err
 	}
 
-	if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
-		file, err := os.OpenFile(configFilePath, os.O_RDWR|os.O_CREATE, 0644)
+	report := &DoctorReport{Fixed: changes}
+	checkConfig(config, report)
+	fixed := len(report.Fixed) > len(changes)
+	movedPATs := false
+
+	for i, provider := range config.Providers {
+		if provider.User == nil {
+			continue
+		}
+		if strings.HasPrefix(provider.User.PATRef, keyringRefPrefix) {
+			kr, err := keyring.Default()
+			if err != nil {
+				return nil, fmt.Errorf("error opening keyring to check PAT of SCM provider %s: %w", provider.Name, err)
+			}
+			_, err = kr.Get(strings.TrimPrefix(provider.User.PATRef, keyringRefPrefix))
+			if errors.Is(err, keyring.ErrNotFound) {
+				report.Problems = append(report.Problems, fmt.Sprintf("PAT of SCM provider %s is missing from the "+
+					"keyring, remove it and add it again", provider.Name))
+			} else if err != nil {
+				return nil, fmt.Errorf("error checking PAT of SCM provider %s: %w", provider.Name, err)
+			}
+		} else if provider.User.PAT != "" {
+			storedProvider, err := s.storePAT(provider)
+			if err != nil {
+				return nil, err
+			}
+			config.Providers[i] = storedProvider
+			report.Fixed = append(report.Fixed, fmt.Sprintf("moved the plaintext PAT of SCM provider %s to the keyring",
+				provider.Name))
+			fixed = true
+			movedPATs = true
+		}
+	}
+
+	if fixed {
+		err = s.writeConfig(config)
 		if err != nil {
-			return nil, fmt.Errorf("could not create SCM provider config file %s: %w", configFilePath, err)
+			return nil, fmt.Errorf("error writing fixed SCM provider config: %w", err)
 		}
-		defer file.Close()
 	}
+	if movedPATs {
+		err = removeConfigBackup()
+		if err != nil {
+			return nil, fmt.Errorf("error removing backup holding plaintext PATs: %w", err)
+		}
+	}
+	return report, nil
+}
 
-	content, err := os.ReadFile(configFilePath)
+func (s *scmProviderImpl) CreateProfile(profile types.Profile) error {
+	unlock, err := lockConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error reading SCM provider config file %s: %w", configFilePath, err)
+		return err
 	}
+	defer unlock()
 
-	var config = &types.SCMConfig{}
-	err = yaml.Unmarshal(content, &config)
+	config, existingProviders, err := s.readYAML()
+	if err != nil {
+		return fmt.Errorf("error listing existing SCM providers before creating profile: %w", err)
+	}
+
+	err = addProfile(config, existingProviders, profile)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeYAML(config, existingProviders)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+func (s *scmProviderImpl) UseProfile(name string) error {
+	return s.updateProfiles(func(config *types.SCMConfig) error {
+		return useProfile(config, name)
+	})
+}
+
+func (s *scmProviderImpl) DeleteProfile(name string) error {
+	return s.updateProfiles(func(config *types.SCMConfig) error {
+		return deleteProfile(config, name)
+	})
+}
+
+func (s *scmProviderImpl) ListProfiles() ([]*types.Profile, string, error) {
+	unlock, err := lockConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error deserialising SCM provider config: %w", err)
+		return nil, "", err
 	}
+	defer unlock()
+
+	config, err := s.readConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing profiles: %w", err)
+	}
+	profiles, current := getProfiles(config)
+	return profiles, current, nil
+}
+
+// updateProfiles applies the update to the profiles of the config, the providers are written back as they are.
+func (s *scmProviderImpl) updateProfiles(update func(config *types.SCMConfig) error) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := s.readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading SCM provider config before updating profiles: %w", err)
+	}
+
+	err = update(config)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeConfig(config)
+	if err != nil {
+		return fmt.Errorf("error writing SCM provider config: %w", err)
+	}
+	return nil
+}
+
+// readYAML reads the config along with its providers by name, with the PATs kept in the keyring resolved.
+func (s *scmProviderImpl) readYAML() (*types.SCMConfig, map[string]*types.SCMProvider, error) {
+	config, err := s.readConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var plaintextPATs []string
 	providerMap := map[string]*types.SCMProvider{}
 	for _, provider := range config.Providers {
+		if provider == nil {
+			continue
+		}
+		if provider.User != nil {
+			if strings.HasPrefix(provider.User.PATRef, keyringRefPrefix) {
+				err = s.resolvePAT(provider)
+				if err != nil {
+					return nil, nil, err
+				}
+			} else if provider.User.PAT != "" {
+				plaintextPATs = append(plaintextPATs, provider.Name)
+			}
+		}
 		providerMap[provider.Name] = provider
 	}
 
-	return providerMap, nil
+	// NOTE: Configs written before PATs moved to the keyring hold them in plain text, writing the config back moves
+	// them out of it.
+	if len(plaintextPATs) > 0 {
+		err = s.writeYAML(config, providerMap)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error moving plaintext PATs to the keyring: %w", err)
+		}
+		err = removeConfigBackup()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error removing backup holding plaintext PATs: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Moved the PATs of SCM providers %s from the config file to the keyring\n",
+			strings.Join(plaintextPATs, ", "))
+	}
+
+	return config, providerMap, nil
 }
 
-func (s *scmProviderImpl) writeYAML(providerMap map[string]*types.SCMProvider) error {
-	configFilePath, err := s.getConfigFilePath()
+func (s *scmProviderImpl) resolvePAT(provider *types.SCMProvider) error {
+	kr, err := keyring.Default()
 	if err != nil {
-		return fmt.Errorf("error getting SCM provider config file path before writing: %w", err)
+		return fmt.Errorf("error opening keyring to read PAT of SCM provider %s: %w", provider.Name, err)
+	}
+	key := strings.TrimPrefix(provider.User.PATRef, keyringRefPrefix)
+	pat, err := kr.Get(key)
+	if err != nil {
+		return fmt.Errorf("error reading PAT of SCM provider %s: %w", provider.Name, err)
+	}
+	provider.User.PAT = pat
+	s.rememberKeyringPAT(key, pat)
+	return nil
+}
+
+// readConfig reads the config file as it is, without resolving the PATs kept in the keyring.
+func (s *scmProviderImpl) readConfig() (*types.SCMConfig, error) {
+	config, _, err := s.loadConfig()
+	return config, err
+}
+
+// loadConfig reads the config file and upgrades it to the current version, describing what the upgrade changed.
+func (s *scmProviderImpl) loadConfig() (*types.SCMConfig, []string, error) {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting SCM provider config file path before reading: %w", err)
+	}
+
+	content, err := readConfigFile(configFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	// NOTE: The encrypted config is JSON, which would deserialise as an empty plaintext config.
+	if encryption.IsEncrypted(content) {
+		return nil, nil, fmt.Errorf("SCM provider config file %s is encrypted", configFilePath)
+	}
+	if len(content) == 0 {
+		return &types.SCMConfig{Version: configVersion}, nil, nil
 	}
 
+	var config = &types.SCMConfig{}
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error deserialising SCM provider config: %w", err)
+	}
+	if config == nil {
+		config = &types.SCMConfig{}
+	}
+
+	version := config.Version
+	changes, err := migrateConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.Version != version {
+		err = s.writeConfig(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error writing upgraded SCM provider config: %w", err)
+		}
+	}
+
+	return config, changes, nil
+}
+
+// writeYAML writes the providers, keeping the rest of the config, eg the profiles, as it is.
+func (s *scmProviderImpl) writeYAML(config *types.SCMConfig, providerMap map[string]*types.SCMProvider) error {
 	providers := make([]*types.SCMProvider, 0)
 	for name, provider := range providerMap {
 		if name != "" && provider != nil {
-			providers = append(providers, provider)
+			storedProvider, err := s.storePAT(provider)
+			if err != nil {
+				return err
+			}
+			providers = append(providers, storedProvider)
 		}
 	}
-	config := &types.SCMConfig{Providers: providers}
+	return s.writeConfig(&types.SCMConfig{
+		Providers:      providers,
+		Profiles:       config.Profiles,
+		CurrentProfile: config.CurrentProfile,
+	})
+}
 
+// writeConfig writes the config file as it is, at the current version.
+func (s *scmProviderImpl) writeConfig(config *types.SCMConfig) error {
+	configFilePath, err := getConfigFilePath()
+	if err != nil {
+		return fmt.Errorf("error getting SCM provider config file path before writing: %w", err)
+	}
+
+	config.Version = configVersion
 	yamlData, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("error serialising SCM provider config: %w", err)
 	}
 
-	err = os.WriteFile(configFilePath, yamlData, 0644)
+	err = writeConfigFile(configFilePath, yamlData)
 	if err != nil {
 		return fmt.Errorf("error writing SCM provider config: %w", err)
 	}
@@ -175,8 +467,56 @@ func (s *scmProviderImpl) writeYAML(providerMap map[string]*types.SCMProvider) e
 	return nil
 }
 
+// storePAT moves the PAT of the provider to the keyring and returns a copy of the provider that only references it. PATs
+// already in the keyring are only written again when they changed.
+func (s *scmProviderImpl) storePAT(provider *types.SCMProvider) (*types.SCMProvider, error) {
+	if provider.User == nil || provider.User.PAT == "" {
+		return provider, nil
+	}
+
+	key, err := getKeyringKey(provider.Name)
+	if err != nil {
+		return nil, err
+	}
+	if provider.User.PATRef != keyringRefPrefix+key || !s.isInKeyring(key, provider.User.PAT) {
+		kr, err := keyring.Default()
+		if err != nil {
+			return nil, fmt.Errorf("error opening keyring to store PAT of SCM provider %s: %w", provider.Name, err)
+		}
+		err = kr.Set(key, provider.User.PAT)
+		if err != nil {
+			return nil, fmt.Errorf("error storing PAT of SCM provider %s: %w", provider.Name, err)
+		}
+		s.rememberKeyringPAT(key, provider.User.PAT)
+	}
+
+	storedUser := *provider.User
+	storedUser.PAT = ""
+	storedUser.PATRef = keyringRefPrefix + key
+	storedProvider := *provider
+	storedProvider.User = &storedUser
+	return &storedProvider, nil
+}
+
+func (s *scmProviderImpl) rememberKeyringPAT(key string, pat string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keyringPATs == nil {
+		s.keyringPATs = map[string]string{}
+	}
+	s.keyringPATs[key] = pat
+}
+
+// isInKeyring reports whether the PAT is the one read from or last written to the keyring under the key.
+func (s *scmProviderImpl) isInKeyring(key string, pat string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.keyringPATs[key]
+	return ok && stored == pat
+}
+
 func (s *scmProviderImpl) deleteYAML() error {
-	configFilePath, err := s.getConfigFilePath()
+	configFilePath, err := getConfigFilePath()
 	if err != nil {
 		return fmt.Errorf("error getting SCM provider config file path before purging: %w", err)
 	}
@@ -195,5 +535,106 @@ func (s *scmProviderImpl) deleteYAML() error {
 		return fmt.Errorf("error purging SCM provider config: %w", err)
 	}
 
+	// NOTE: The lock file is left behind as other processes may be waiting on it.
+	for _, suffix := range []string{backupFileSuffix, corruptFileSuffix} {
+		err = os.Remove(configFilePath + suffix)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error purging SCM provider config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addProvider adds the provider, and adds it to the profile in use if any.
+func addProvider(config *types.SCMConfig, existingProviders map[string]*types.SCMProvider,
+	provider types.SCMProvider) error {
+	if existingProviders[provider.Name] != nil {
+		return fmt.Errorf("SCM provider %s already exists", provider.Name)
+	}
+	if err := validateAPI(&provider); err != nil {
+		return err
+	}
+	profile, err := getActiveProfile(config)
+	if err != nil {
+		return err
+	}
+
+	provider.Updated = time.Now().UnixMilli()
+	provider.Created = time.Now().UnixMilli()
+
+	existingProviders[provider.Name] = &provider
+	if profile != nil {
+		profile.Providers = append(profile.Providers, provider.Name)
+	}
+	return nil
+}
+
+func updateProviders(existingProviders map[string]*types.SCMProvider, providers []types.SCMProvider) error {
+	for _, provider := range providers {
+		if existingProviders[provider.Name] == nil {
+			return fmt.Errorf("SCM provider %s does not exist", provider.Name)
+		}
+		if err := validateAPI(&provider); err != nil {
+			return err
+		}
+		provider.Updated = time.Now().UnixMilli()
+		existingProviders[provider.Name] = &provider
+	}
+	return nil
+}
+
+// filterProviders returns the providers of the profile in use matching the type and name, either of which may be empty
+// to match any.
+func filterProviders(config *types.SCMConfig, providerMap map[string]*types.SCMProvider, providerType string,
+	providerName string) ([]*types.SCMProvider, error) {
+	profile, err := getActiveProfile(config)
+	if err != nil {
+		return nil, err
+	}
+	providers := make([]*types.SCMProvider, 0)
+	for name, provider := range providerMap {
+		if name != "" && provider != nil &&
+			(profile == nil || slices.Contains(profile.Providers, name)) &&
+			(providerName == "" || providerName == provider.Name) &&
+			(providerType == "" || providerType == provider.Type) {
+			providers = append(providers, provider)
+		}
+	}
+	return providers, nil
+}
+
+// listAll returns every provider sorted by name regardless of the profile in use, the profiles and the name of the
+// profile set with `prm profile use`.
+func listAll(config *types.SCMConfig, providerMap map[string]*types.SCMProvider) ([]*types.SCMProvider,
+	[]*types.Profile, string) {
+	providers := make([]*types.SCMProvider, 0, len(providerMap))
+	for name, provider := range providerMap {
+		if name != "" && provider != nil {
+			providers = append(providers, provider)
+		}
+	}
+	slices.SortFunc(providers, func(a, b *types.SCMProvider) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	profiles, _ := getProfiles(config)
+	return providers, profiles, config.CurrentProfile
+}
+
+// isProviderInScope reports whether the provider exists and belongs to the profile in use if any.
+func isProviderInScope(config *types.SCMConfig, providerMap map[string]*types.SCMProvider, name string) (bool, error) {
+	providers, err := filterProviders(config, providerMap, "", name)
+	if err != nil {
+		return false, err
+	}
+	return len(providers) > 0, nil
+}
+
+// validateAPI rejects an api on providers other than github, the only one which lets the API be chosen.
+func validateAPI(provider *types.SCMProvider) error {
+	if provider.API != "" && provider.Type != "github" {
+		return fmt.Errorf("SCM provider %s of type %s does not support choosing the api, only github does",
+			provider.Name, provider.Type)
+	}
 	return nil
 }
@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Limiter caps the number of requests, or of other pieces of work, in flight. A nil Limiter does not limit anything.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing up to limit concurrent requests, or nil when limit is not positive.
+func NewLimiter(limit int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, limit)}
+}
+
+// Acquire waits for a free slot, it has to be freed with Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("gave up waiting for a free slot: %w", ctx.Err())
+	}
+}
+
+func (l *Limiter) Release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// LimitTransport is an http.RoundTripper that holds a slot of each of its limiters from sending a request until its
+// response body is closed. The limiters are always acquired in order, so limiters shared between transports must be
+// passed last.
+type LimitTransport struct {
+	Base     http.RoundTripper
+	Limiters []*Limiter
+}
+
+var _ http.RoundTripper = (*LimitTransport)(nil)
+
+func NewLimitTransport(base http.RoundTripper, limiters ...*Limiter) *LimitTransport {
+	return &LimitTransport{
+		Base:     base,
+		Limiters: limiters,
+	}
+}
+
+func (t *LimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for i, limiter := range t.Limiters {
+		if err := limiter.Acquire(r.Context()); err != nil {
+			t.release(i)
+			return nil, err
+		}
+	}
+
+	response, err := t.Base.RoundTrip(r)
+	if err != nil {
+		t.release(len(t.Limiters))
+		return nil, err
+	}
+	response.Body = &releasingBody{
+		ReadCloser: response.Body,
+		release: func() {
+			t.release(len(t.Limiters))
+		},
+	}
+	return response, nil
+}
+
+// release frees the slots of the first count limiters.
+func (t *LimitTransport) release(count int) {
+	for i := count - 1; i >= 0; i-- {
+		t.Limiters[i].Release()
+	}
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
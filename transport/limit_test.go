@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire of slot %d returned error: %v", i, err)
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Acquire(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire with every slot taken returned %v, want the deadline to be exceeded", err)
+	}
+
+	limiter.Release()
+	if err := limiter.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire after a Release returned error: %v", err)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	limiter := NewLimiter(0)
+	if limiter != nil {
+		t.Fatalf("NewLimiter(0) returned %v, want nil", limiter)
+	}
+	for i := 0; i < 100; i++ {
+		if err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire of a nil limiter returned error: %v", err)
+		}
+	}
+	limiter.Release()
+}
+
+func TestLimitTransport(t *testing.T) {
+	const limit = 2
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	providerLimiter := NewLimiter(limit)
+	globalLimiter := NewLimiter(limit + 1)
+	client := &http.Client{Transport: NewLimitTransport(http.DefaultTransport, providerLimiter, globalLimiter)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("request returned error: %v", err)
+				return
+			}
+			response.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got > limit {
+		t.Errorf("%d requests were in flight at once, want at most %d", got, limit)
+	}
+	assertReleased(t, "provider", providerLimiter, limit)
+	assertReleased(t, "global", globalLimiter, limit+1)
+}
+
+func TestLimitTransportReleasesOnError(t *testing.T) {
+	limiter := NewLimiter(1)
+	client := &http.Client{Transport: NewLimitTransport(http.DefaultTransport, limiter)}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("http://127.0.0.1:0"); err == nil {
+			t.Fatal("request to a closed port returned no error")
+		}
+	}
+	assertReleased(t, "provider", limiter, 1)
+}
+
+func TestLimitTransportReleasesOnceOnDoubleClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	limiter := NewLimiter(2)
+	client := &http.Client{Transport: NewLimitTransport(http.DefaultTransport, limiter)}
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire returned error: %v", err)
+	}
+	response, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request returned error: %v", err)
+	}
+	response.Body.Close()
+	response.Body.Close()
+
+	if got := len(limiter.slots); got != 1 {
+		t.Errorf("%d slots are taken after closing the body twice, want the 1 held outside the transport", got)
+	}
+}
+
+// assertReleased checks that every slot of the limiter is free once the requests are done.
+func assertReleased(t *testing.T, name string, limiter *Limiter, limit int) {
+	t.Helper()
+	if got := len(limiter.slots); got != 0 {
+		t.Errorf("%d slots of the %s limiter are still taken, want all %d free", got, name, limit)
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 4
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+
+	// idempotencyKeyHeader follows the net/http convention for marking non-GET requests as safe to retry. Setting
+	// the key to a nil slice marks the request without sending the header.
+	idempotencyKeyHeader = "X-Idempotency-Key"
+)
+
+// RetryTransport is an http.RoundTripper that retries idempotent requests which failed because of rate limiting or
+// server errors. It waits for as long as the Retry-After or X-RateLimit-Reset headers ask for and falls back to
+// exponential backoff with full jitter otherwise. Every response is recorded in the Stats of the transport.
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Stats      *Stats
+}
+
+var _ http.RoundTripper = (*RetryTransport)(nil)
+
+func NewRetryTransport(base http.RoundTripper, stats *Stats) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+		Stats:      stats,
+	}
+}
+
+// MarkIdempotent allows the transport to retry a request whose method is not idempotent by definition, eg a
+// GraphQL query or a dry run sent as a POST.
+func MarkIdempotent(r *http.Request) {
+	r.Header[idempotencyKeyHeader] = nil
+}
+
+func (t *RetryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := t.Base.RoundTrip(r)
+		if err == nil {
+			t.Stats.record(r.URL.Host, response.Header, attempt > 0)
+		}
+		if attempt >= t.MaxRetries || !isIdempotent(r) || !shouldRetry(response, err) {
+			return response, err
+		}
+
+		delay, ok := t.retryDelay(response, attempt)
+		if !ok {
+			return response, err
+		}
+
+		if r.Body != nil {
+			if r.GetBody == nil {
+				return response, err
+			}
+			body, bodyErr := r.GetBody()
+			if bodyErr != nil {
+				return response, err
+			}
+			r = r.Clone(r.Context())
+			r.Body = body
+		}
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		if waitErr := wait(r.Context(), delay); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt. It reports false when the server asks for a longer
+// wait than MaxDelay, in which case failing fast is preferable to blocking the command.
+func (t *RetryTransport) retryDelay(response *http.Response, attempt int) (time.Duration, bool) {
+	if response != nil {
+		if delay, ok := retryAfter(response.Header); ok {
+			return delay, delay <= t.MaxDelay
+		}
+		if response.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				delay := time.Until(time.Unix(reset, 0))
+				return max(delay, 0), delay <= t.MaxDelay
+			}
+		}
+	}
+	backoff := t.BaseDelay << attempt
+	if backoff <= 0 || backoff > t.MaxDelay {
+		backoff = t.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1)), true
+}
+
+func shouldRetry(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return true
+	case response.StatusCode == http.StatusForbidden:
+		// NOTE: GitHub answers both primary and secondary rate limits with a 403.
+		return response.Header.Get("Retry-After") != "" || response.Header.Get("X-RateLimit-Remaining") == "0"
+	case response.StatusCode >= http.StatusInternalServerError && response.StatusCode != http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(r *http.Request) bool {
+	switch r.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	_, ok := r.Header[idempotencyKeyHeader]
+	if !ok {
+		_, ok = r.Header["Idempotency-Key"]
+	}
+	return ok
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("gave up retrying request: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryServer returns a server answering every request with the responses in turn, the last one being repeated,
+// along with the number of requests it received.
+func newRetryServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		i := int(requests.Add(1)) - 1
+		responses[min(i, len(responses)-1)](w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func respond(status int, header ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func newTestRetryTransport() *RetryTransport {
+	t := NewRetryTransport(http.DefaultTransport, NewStats())
+	t.BaseDelay = time.Millisecond
+	t.MaxDelay = time.Second
+	return t
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		idempotent   bool
+		responses    []func(w http.ResponseWriter)
+		wantStatus   int
+		wantRequests int32
+	}{
+		{"server error is retried", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusServiceUnavailable), respond(http.StatusOK)},
+			http.StatusOK, 2},
+		{"too many requests is retried after the asked wait", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusTooManyRequests, "Retry-After", "0"), respond(http.StatusOK)},
+			http.StatusOK, 2},
+		{"exhausted rate limit is retried", http.MethodGet, false,
+			[]func(w http.ResponseWriter){
+				respond(http.StatusForbidden, "X-RateLimit-Remaining", "0",
+					"X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10)),
+				respond(http.StatusOK),
+			},
+			http.StatusOK, 2},
+		{"forbidden is not retried", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusForbidden)},
+			http.StatusForbidden, 1},
+		{"not implemented is not retried", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusNotImplemented)},
+			http.StatusNotImplemented, 1},
+		{"wait longer than the max delay fails fast", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusTooManyRequests, "Retry-After", "60")},
+			http.StatusTooManyRequests, 1},
+		{"gives up after the max retries", http.MethodGet, false,
+			[]func(w http.ResponseWriter){respond(http.StatusBadGateway)},
+			http.StatusBadGateway, defaultMaxRetries + 1},
+		{"post is not retried", http.MethodPost, false,
+			[]func(w http.ResponseWriter){respond(http.StatusServiceUnavailable), respond(http.StatusOK)},
+			http.StatusServiceUnavailable, 1},
+		{"post marked idempotent is retried", http.MethodPost, true,
+			[]func(w http.ResponseWriter){respond(http.StatusServiceUnavailable), respond(http.StatusOK)},
+			http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newRetryServer(t, tt.responses...)
+			transport := newTestRetryTransport()
+
+			r, err := http.NewRequest(tt.method, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatalf("error forming request: %v", err)
+			}
+			if tt.idempotent {
+				MarkIdempotent(r)
+			}
+			response, err := (&http.Client{Transport: transport}).Do(r)
+			if err != nil {
+				t.Fatalf("request returned error: %v", err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("got status %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+			hosts := transport.Stats.Hosts()
+			if len(hosts) != 1 || hosts[0].Requests != int(tt.wantRequests) ||
+				hosts[0].Retries != int(tt.wantRequests)-1 {
+				t.Errorf("got stats %+v, want %d requests and %d retries", hosts, tt.wantRequests, tt.wantRequests-1)
+			}
+		})
+	}
+}
+
+func TestRetryTransportGivesUpWhenCanceled(t *testing.T) {
+	server, requests := newRetryServer(t, respond(http.StatusServiceUnavailable))
+	transport := newTestRetryTransport()
+	transport.BaseDelay = time.Minute
+	transport.MaxDelay = time.Minute
+
+	r, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("error forming request: %v", err)
+	}
+	client := &http.Client{Transport: transport, Timeout: 100 * time.Millisecond}
+	_, err = client.Do(r)
+	if err == nil {
+		t.Fatal("request returned no error, want the timeout")
+	}
+	if got := requests.Load(); got > 2 {
+		t.Errorf("server got %d requests, want the retries to stop once the request is canceled", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"seconds", "7", 7 * time.Second, true},
+		{"seconds with spaces", " 2 ", 2 * time.Second, true},
+		{"date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"missing", "", 0, false},
+		{"invalid", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Retry-After", tt.value)
+			got, ok := retryAfter(header)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("retryAfter(%q) returned %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	t.Run("date in the future", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		got, ok := retryAfter(header)
+		if !ok || got <= 59*time.Minute || got > time.Hour {
+			t.Errorf("retryAfter returned %v, %v, want about an hour", got, ok)
+		}
+	})
+}
+
+func TestRetryDelayBackoff(t *testing.T) {
+	transport := newTestRetryTransport()
+	transport.BaseDelay = 100 * time.Millisecond
+	transport.MaxDelay = 300 * time.Millisecond
+
+	for attempt, ceiling := range []time.Duration{100, 200, 300, 300, 300} {
+		delay, ok := transport.retryDelay(nil, attempt)
+		if !ok || delay < 0 || delay > ceiling*time.Millisecond {
+			t.Errorf("attempt %d waits %v, %v, want at most %v", attempt, delay, ok, ceiling*time.Millisecond)
+		}
+	}
+}
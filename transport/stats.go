@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stats keeps track of the requests sent to every host along with the last rate limit quota reported by it.
+type Stats struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+type HostStats struct {
+	Host      string
+	Requests  int
+	Retries   int
+	Remaining int
+	Limit     int
+	HasQuota  bool
+}
+
+// rateLimitHeaders lists the quota headers used by the supported SCM providers, GitHub and Gitea use the
+// X-RateLimit-* flavour while GitLab uses the IETF draft names.
+var rateLimitHeaders = [][2]string{
+	{"X-RateLimit-Remaining", "X-RateLimit-Limit"},
+	{"RateLimit-Remaining", "RateLimit-Limit"},
+}
+
+func NewStats() *Stats {
+	return &Stats{hosts: map[string]*HostStats{}}
+}
+
+func (s *Stats) record(host string, header http.Header, retry bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hostStats := s.hosts[host]
+	if hostStats == nil {
+		hostStats = &HostStats{Host: host}
+		s.hosts[host] = hostStats
+	}
+	hostStats.Requests++
+	if retry {
+		hostStats.Retries++
+	}
+
+	for _, names := range rateLimitHeaders {
+		remaining, err := strconv.Atoi(header.Get(names[0]))
+		if err != nil {
+			continue
+		}
+		hostStats.Remaining = remaining
+		hostStats.HasQuota = true
+		if limit, err := strconv.Atoi(header.Get(names[1])); err == nil {
+			hostStats.Limit = limit
+		}
+		break
+	}
+}
+
+// Hosts returns a snapshot of the stats of every host, sorted by host name.
+func (s *Stats) Hosts() []HostStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hosts := make([]HostStats, 0, len(s.hosts))
+	for _, hostStats := range s.hosts {
+		hosts = append(hosts, *hostStats)
+	}
+	slices.SortFunc(hosts, func(a, b HostStats) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+	return hosts
+}
+
+// PrintSummary writes the number of requests, retries and the remaining quota of every host contacted.
+func (s *Stats) PrintSummary(w io.Writer) {
+	hosts := s.Hosts()
+	if len(hosts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%-40s\t%-10s\t%-10s\t%-20s\n", "Host", "Requests", "Retries", "Rate limit remaining")
+	for _, hostStats := range hosts {
+		quota := "-"
+		if hostStats.HasQuota {
+			quota = strconv.Itoa(hostStats.Remaining)
+			if hostStats.Limit > 0 {
+				quota = fmt.Sprintf("%d/%d", hostStats.Remaining, hostStats.Limit)
+			}
+		}
+		fmt.Fprintf(w, "%-40s\t%-10d\t%-10d\t%-20s\n", hostStats.Host, hostStats.Requests, hostStats.Retries, quota)
+	}
+}
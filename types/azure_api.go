@@ -0,0 +1,77 @@
+package types
+
+type AzureProfile struct {
+	ID           string `json:"id"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type AzureConnectionData struct {
+	AuthenticatedUser AzureIdentity `json:"authenticatedUser"`
+}
+
+type AzureIdentity struct {
+	ID                  string `json:"id"`
+	ProviderDisplayName string `json:"providerDisplayName"`
+}
+
+type AzureAccountList struct {
+	Value []*AzureAccount `json:"value"`
+}
+
+type AzureAccount struct {
+	AccountName string `json:"accountName"`
+}
+
+type AzureProjectList struct {
+	Value []*AzureProject `json:"value"`
+}
+
+type AzureProject struct {
+	Name string `json:"name"`
+}
+
+type AzureRepositoryList struct {
+	Value []*AzureRepository `json:"value"`
+}
+
+type AzureRepository struct {
+	Name       string `json:"name"`
+	IsDisabled bool   `json:"isDisabled"`
+}
+
+type AzurePullRequestList struct {
+	Value []*AzurePullRequest `json:"value"`
+}
+
+type AzurePullRequest struct {
+	PullRequestID int              `json:"pullRequestId"`
+	Title         string           `json:"title"`
+	Status        string           `json:"status"`
+	MergeStatus   string           `json:"mergeStatus"`
+	Reviewers     []*AzureReviewer `json:"reviewers"`
+}
+
+type AzureReviewer struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Vote        int    `json:"vote"`
+}
+
+type AzureThreadList struct {
+	Value []*AzureThread `json:"value"`
+}
+
+type AzureThread struct {
+	Comments []*AzureComment `json:"comments"`
+}
+
+type AzureComment struct {
+	Author      AzureCommentAuthor `json:"author"`
+	CommentType string             `json:"commentType"`
+}
+
+type AzureCommentAuthor struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
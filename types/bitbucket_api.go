@@ -0,0 +1,114 @@
+package types
+
+type BitbucketCloudUser struct {
+	AccountID   string `json:"account_id"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}
+
+type BitbucketCloudPullRequestPage struct {
+	Values []*BitbucketCloudPullRequest `json:"values"`
+	Next   string                       `json:"next"`
+}
+
+type BitbucketCloudPullRequest struct {
+	ID           int                          `json:"id"`
+	Title        string                       `json:"title"`
+	State        string                       `json:"state"`
+	Links        BitbucketCloudLinks          `json:"links"`
+	Destination  BitbucketCloudBranchRef      `json:"destination"`
+	Participants []*BitbucketCloudParticipant `json:"participants"`
+}
+
+type BitbucketCloudLinks struct {
+	HTML BitbucketCloudLink `json:"html"`
+}
+
+type BitbucketCloudLink struct {
+	Href string `json:"href"`
+}
+
+type BitbucketCloudBranchRef struct {
+	Repository BitbucketCloudRepository `json:"repository"`
+}
+
+type BitbucketCloudRepository struct {
+	FullName string `json:"full_name"`
+}
+
+type BitbucketCloudParticipant struct {
+	User           BitbucketCloudUser `json:"user"`
+	Role           string             `json:"role"`
+	Approved       bool               `json:"approved"`
+	State          *string            `json:"state"`
+	ParticipatedOn *string            `json:"participated_on"`
+}
+
+type BitbucketCloudDiffStatPage struct {
+	Values []*BitbucketCloudDiffStat `json:"values"`
+	Next   string                    `json:"next"`
+}
+
+type BitbucketCloudDiffStat struct {
+	Status string `json:"status"`
+}
+
+type BitbucketDCUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+}
+
+type BitbucketDCPullRequestPage struct {
+	Values        []*BitbucketDCPullRequest `json:"values"`
+	IsLastPage    bool                      `json:"isLastPage"`
+	NextPageStart int                       `json:"nextPageStart"`
+}
+
+type BitbucketDCPullRequest struct {
+	ID           int                       `json:"id"`
+	Title        string                    `json:"title"`
+	State        string                    `json:"state"`
+	FromRef      BitbucketDCRef            `json:"fromRef"`
+	ToRef        BitbucketDCRef            `json:"toRef"`
+	Reviewers    []*BitbucketDCParticipant `json:"reviewers"`
+	Participants []*BitbucketDCParticipant `json:"participants"`
+	Links        BitbucketDCLinks          `json:"links"`
+}
+
+type BitbucketDCRef struct {
+	Repository   BitbucketDCRepository `json:"repository"`
+	LatestCommit string                `json:"latestCommit"`
+}
+
+type BitbucketDCRepository struct {
+	Slug    string             `json:"slug"`
+	Project BitbucketDCProject `json:"project"`
+}
+
+type BitbucketDCProject struct {
+	Key string `json:"key"`
+}
+
+type BitbucketDCParticipant struct {
+	User   BitbucketDCUser `json:"user"`
+	Role   string          `json:"role"`
+	Status string          `json:"status"`
+	// LastReviewedCommit is only set for reviewers.
+	LastReviewedCommit string `json:"lastReviewedCommit"`
+}
+
+type BitbucketDCLinks struct {
+	Self []BitbucketDCLink `json:"self"`
+}
+
+type BitbucketDCLink struct {
+	Href string `json:"href"`
+}
+
+type BitbucketDCMergeStatus struct {
+	CanMerge   bool `json:"canMerge"`
+	Conflicted bool `json:"conflicted"`
+}
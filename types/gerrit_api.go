@@ -0,0 +1,41 @@
+package types
+
+type GerritAccount struct {
+	AccountID int64  `json:"_account_id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	// Tags hold SERVICE_USER for automated accounts.
+	Tags []string `json:"tags"`
+}
+
+type GerritChange struct {
+	Number      int                     `json:"_number"`
+	Project     string                  `json:"project"`
+	Subject     string                  `json:"subject"`
+	Status      string                  `json:"status"`
+	Submittable bool                    `json:"submittable"`
+	Labels      map[string]*GerritLabel `json:"labels"`
+	Owner       GerritAccount           `json:"owner"`
+	Messages    []*GerritMessage        `json:"messages"`
+	MoreChanges bool                    `json:"_more_changes"`
+}
+
+type GerritLabel struct {
+	All []*GerritApproval `json:"all"`
+}
+
+type GerritApproval struct {
+	GerritAccount
+	Value int `json:"value"`
+}
+
+type GerritComment struct {
+	Author GerritAccount `json:"author"`
+}
+
+type GerritMessage struct {
+	Author  *GerritAccount `json:"author"`
+	Tag     string         `json:"tag"`
+	Message string         `json:"message"`
+}
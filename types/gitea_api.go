@@ -0,0 +1,48 @@
+package types
+
+type GiteaUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+type GiteaIssue struct {
+	Number      int                    `json:"number"`
+	Title       string                 `json:"title"`
+	State       string                 `json:"state"`
+	HTMLURL     string                 `json:"html_url"`
+	Repository  GiteaIssueRepository   `json:"repository"`
+	PullRequest *GiteaIssuePullRequest `json:"pull_request"`
+}
+
+type GiteaIssueRepository struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+type GiteaIssuePullRequest struct {
+	Merged bool `json:"merged"`
+}
+
+type GiteaPullRequest struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	HTMLURL   string `json:"html_url"`
+	Mergeable bool   `json:"mergeable"`
+	Merged    bool   `json:"merged"`
+}
+
+type GiteaReview struct {
+	State     string    `json:"state"`
+	User      GiteaUser `json:"user"`
+	Dismissed bool      `json:"dismissed"`
+	// Stale is set for the reviews of commits which are no longer the head of the PR.
+	Stale bool `json:"stale"`
+	// Team is set instead of the user for the review requests of teams.
+	Team *GiteaTeam `json:"team"`
+}
+
+type GiteaTeam struct {
+	Name string `json:"name"`
+}
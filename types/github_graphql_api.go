@@ -0,0 +1,127 @@
+package types
+
+import "time"
+
+type GithubGraphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type GithubGraphQLResponse struct {
+	Data   GithubGraphQLData     `json:"data"`
+	Errors []*GithubGraphQLError `json:"errors"`
+}
+
+type GithubGraphQLError struct {
+	Message string `json:"message"`
+}
+
+type GithubGraphQLData struct {
+	Search GithubGraphQLSearch `json:"search"`
+}
+
+type GithubGraphQLSearch struct {
+	IssueCount int                         `json:"issueCount"`
+	PageInfo   GithubGraphQLPageInfo       `json:"pageInfo"`
+	Nodes      []*GithubGraphQLPullRequest `json:"nodes"`
+}
+
+type GithubGraphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type GithubGraphQLPullRequest struct {
+	Number           int                         `json:"number"`
+	Title            string                      `json:"title"`
+	URL              string                      `json:"url"`
+	State            string                      `json:"state"`
+	Merged           bool                        `json:"merged"`
+	Mergeable        string                      `json:"mergeable"`
+	MergeStateStatus string                      `json:"mergeStateStatus"`
+	IsDraft          bool                        `json:"isDraft"`
+	HeadRefOid       string                      `json:"headRefOid"`
+	HeadRefName      string                      `json:"headRefName"`
+	BaseRefName      string                      `json:"baseRefName"`
+	CreatedAt        time.Time                   `json:"createdAt"`
+	UpdatedAt        time.Time                   `json:"updatedAt"`
+	MergedAt         *time.Time                  `json:"mergedAt"`
+	Repository       GithubGraphQLRepository     `json:"repository"`
+	Author           GithubGraphQLActor          `json:"author"`
+	Labels           GithubGraphQLLabels         `json:"labels"`
+	Reviews          GithubGraphQLReviews        `json:"reviews"`
+	ReviewRequests   GithubGraphQLReviewRequests `json:"reviewRequests"`
+	Commits          GithubGraphQLCommits        `json:"commits"`
+}
+
+type GithubGraphQLRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+}
+
+type GithubGraphQLLabels struct {
+	Nodes []*GithubGraphQLLabel `json:"nodes"`
+}
+
+type GithubGraphQLLabel struct {
+	Name string `json:"name"`
+}
+
+type GithubGraphQLReviews struct {
+	Nodes []*GithubGraphQLReview `json:"nodes"`
+}
+
+type GithubGraphQLReview struct {
+	State  string                    `json:"state"`
+	Author GithubGraphQLActor        `json:"author"`
+	Commit GithubGraphQLReviewCommit `json:"commit"`
+}
+
+type GithubGraphQLReviewCommit struct {
+	Oid string `json:"oid"`
+}
+
+type GithubGraphQLActor struct {
+	Login string `json:"login"`
+	// CombinedSlug is set for teams only, eg org/team.
+	CombinedSlug string `json:"combinedSlug"`
+}
+
+type GithubGraphQLReviewRequests struct {
+	Nodes []*GithubGraphQLReviewRequest `json:"nodes"`
+}
+
+type GithubGraphQLReviewRequest struct {
+	RequestedReviewer GithubGraphQLActor `json:"requestedReviewer"`
+}
+
+type GithubGraphQLCommits struct {
+	Nodes []*GithubGraphQLCommitNode `json:"nodes"`
+}
+
+type GithubGraphQLCommitNode struct {
+	Commit GithubGraphQLCommit `json:"commit"`
+}
+
+type GithubGraphQLCommit struct {
+	StatusCheckRollup *GithubGraphQLStatusCheckRollup `json:"statusCheckRollup"`
+}
+
+type GithubGraphQLStatusCheckRollup struct {
+	State    string                     `json:"state"`
+	Contexts GithubGraphQLCheckContexts `json:"contexts"`
+}
+
+type GithubGraphQLCheckContexts struct {
+	Nodes []*GithubGraphQLCheckContext `json:"nodes"`
+}
+
+// GithubGraphQLCheckContext is either a check run, with a name, status and conclusion, or a commit status, with a
+// context and state.
+type GithubGraphQLCheckContext struct {
+	TypeName   string `json:"__typename"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	Context    string `json:"context"`
+	State      string `json:"state"`
+}
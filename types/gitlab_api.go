@@ -0,0 +1,36 @@
+package types
+
+type GitlabUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type GitlabMergeRequest struct {
+	IID                 int          `json:"iid"`
+	ProjectID           int64        `json:"project_id"`
+	Title               string       `json:"title"`
+	State               string       `json:"state"`
+	WebURL              string       `json:"web_url"`
+	DetailedMergeStatus string       `json:"detailed_merge_status"`
+	Reviewers           []GitlabUser `json:"reviewers"`
+}
+
+type GitlabApprovals struct {
+	ApprovedBy []GitlabApprovedBy `json:"approved_by"`
+}
+
+type GitlabApprovedBy struct {
+	User GitlabUser `json:"user"`
+}
+
+type GitlabDiscussion struct {
+	Notes []GitlabNote `json:"notes"`
+}
+
+type GitlabNote struct {
+	Author     GitlabUser `json:"author"`
+	System     bool       `json:"system"`
+	Resolvable bool       `json:"resolvable"`
+	Resolved   bool       `json:"resolved"`
+}
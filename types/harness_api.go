@@ -38,6 +38,19 @@ type PRData struct {
 	State            string `json:"state"`
 	SourceSHA        string `json:"source_sha"`
 	MergeCheckStatus string `json:"merge_check_status"`
+	IsDraft          bool   `json:"is_draft"`
+	SourceBranch     string `json:"source_branch"`
+	TargetBranch     string `json:"target_branch"`
+	// NOTE: Created, Updated and Merged are unix timestamps in milliseconds, Merged is null for unmerged PRs.
+	Created int64            `json:"created"`
+	Updated int64            `json:"updated"`
+	Merged  *int64           `json:"merged"`
+	Author  PRActivityAuthor `json:"author"`
+	Labels  []*PRLabel       `json:"labels"`
+}
+
+type PRLabel struct {
+	Key string `json:"key"`
 }
 
 type PRDetailsData struct {
@@ -48,18 +61,39 @@ type PRDetailsData struct {
 }
 
 type PRActivity struct {
-	PRActivityDecision PRActivityDecision `json:"payload"`
-	PRActivityAuthor   PRActivityAuthor   `json:"author"`
-	Type               string             `json:"type"`
+	PRActivityAuthor PRActivityAuthor `json:"author"`
+	Type             string           `json:"type"`
 }
 
-type PRActivityDecision struct {
-	Decision *string `json:"decision"`
-}
 type PRActivityAuthor struct {
 	DisplayName string `json:"display_name"`
 }
 
+type PRReviewer struct {
+	Reviewer       PRReviewerPrincipal `json:"reviewer"`
+	ReviewDecision string              `json:"review_decision"`
+	// SHA is the commit the reviewer last reviewed.
+	SHA string `json:"sha"`
+}
+
+type PRReviewerPrincipal struct {
+	ID          int64  `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type PRChecks struct {
+	Checks []*PRCheck `json:"checks"`
+}
+
+type PRCheck struct {
+	Check PRCheckData `json:"check"`
+}
+
+type PRCheckData struct {
+	Identifier string `json:"identifier"`
+	Status     string `json:"status"`
+}
+
 type PRMergeRequest struct {
 	BypassRules bool   `json:"bypass_rules"`
 	DryRun      bool   `json:"dry_run"`
@@ -13,6 +13,33 @@ type PullRequest struct {
 	Commented        []string `json:"commented" yaml:"commented"`
 	RequestedChanges []string `json:"requested_changes" yaml:"requested_changes"`
 	Mergeable        string   `json:"mergeable" yaml:"mergeable"`
+	// PendingReviewers are the users and teams whose review is requested but who have neither approved nor requested
+	// changes yet.
+	PendingReviewers []string `json:"pending_reviewers" yaml:"pending_reviewers"`
+	// MyReview is the review of the user listing the PRs:- [approved/requested_changes/commented/pending], empty when
+	// the user has neither reviewed nor been asked to.
+	MyReview string `json:"my_review" yaml:"my_review"`
+	// Checks sums up the CI checks of open PRs, it is nil for other PRs and for providers whose checks are not fetched.
+	Checks *ChecksSummary `json:"checks" yaml:"checks"`
+	Draft  bool           `json:"draft" yaml:"draft"`
+	Labels []string       `json:"labels" yaml:"labels"`
+	// Repo is the full name of the repository of the PR, eg owner/repo.
+	Repo         string `json:"repo" yaml:"repo"`
+	SourceBranch string `json:"source_branch" yaml:"source_branch"`
+	TargetBranch string `json:"target_branch" yaml:"target_branch"`
+	Author       string `json:"author" yaml:"author"`
+	// Created, Updated and Merged are unix timestamps in milliseconds, Merged is zero for PRs which are not merged.
+	Created int64 `json:"created" yaml:"created"`
+	Updated int64 `json:"updated" yaml:"updated"`
+	Merged  int64 `json:"merged" yaml:"merged"`
+}
+
+// ChecksSummary counts the CI checks and commit statuses of the head commit of a PR by outcome.
+type ChecksSummary struct {
+	Passing       int      `json:"passing" yaml:"passing"`
+	Failing       int      `json:"failing" yaml:"failing"`
+	Pending       int      `json:"pending" yaml:"pending"`
+	FailingChecks []string `json:"failing_checks" yaml:"failing_checks"`
 }
 
 type PrintablePullRequest struct {
@@ -28,7 +55,16 @@ type PrintablePullRequest struct {
 	Commented          []string
 	RequestedChanges   []string
 	Mergeable          []string
-	MaxRows            int
+	PendingReviewers   []string
+	MyReview           []string
+	Checks             []string
+	Repo               []string
+	Branches           []string
+	Author             []string
+	Draft              []string
+	Labels             []string
+	Created            []string
+	Updated            []string
 }
 
 type PullRequestResponse struct {
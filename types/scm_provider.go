@@ -1,24 +1,42 @@
 package types
 
 type SCMConfig struct {
+	// Version of the schema of the config, see the migrations of the store.
+	Version   int            `yaml:"version"`
 	Providers []*SCMProvider `yaml:"scm_providers"`
+	Profiles  []*Profile     `yaml:"profiles,omitempty"`
+	// CurrentProfile is the profile set with `prm profile use`, all the providers are used when it is empty.
+	CurrentProfile string `yaml:"current_profile,omitempty"`
+}
+
+// Profile groups SCM providers by name, eg work and oss, so that commands only act on the providers of the profile in
+// use.
+type Profile struct {
+	Name      string   `yaml:"name"`
+	Providers []string `yaml:"providers"`
 }
 
 type SCMProvider struct {
-	Type    string  `yaml:"type"`
-	Name    string  `yaml:"name"`
-	Host    string  `yaml:"host"`
-	User    *User   `yaml:"user"`
-	Repos   []*Repo `yaml:"repos"`
-	Updated int64   `yaml:"updated"`
-	Created int64   `yaml:"created"`
+	Type  string  `yaml:"type"`
+	Name  string  `yaml:"name"`
+	Host  string  `yaml:"host"`
+	User  *User   `yaml:"user"`
+	Repos []*Repo `yaml:"repos"`
+	API   string  `yaml:"api,omitempty"`
+	// Concurrency caps the requests in flight to this provider, on top of the global limit. Zero means no cap.
+	Concurrency int   `yaml:"concurrency,omitempty"`
+	Updated     int64 `yaml:"updated"`
+	Created     int64 `yaml:"created"`
 }
 
 type User struct {
-	Name        string `yaml:"name"`
-	PAT         string `yaml:"pat"`
+	Name string `yaml:"name"`
+	PAT  string `yaml:"pat,omitempty"`
+	// PATRef points at where the PAT is kept when it is not stored in the config file itself, eg keyring:<name>.
+	PATRef      string `yaml:"pat_ref,omitempty"`
 	PrincipalID int64  `yaml:"principal_id"`
 	Email       string `yaml:"email"`
+	AccountID   string `yaml:"account_id"`
 }
 
 type Repo struct {
@@ -27,3 +45,27 @@ type Repo struct {
 	ProjectIdentifier string `yaml:"project_identifier"`
 	RepoIdentifier    string `yaml:"repo_identifier"`
 }
+
+// SCMProviderExport lists SCM providers in the portable form written by `prm export` and read by `prm restore` and
+// `prm import providers`. Only what is needed to add the providers again is kept, the rest is fetched anew.
+type SCMProviderExport struct {
+	Version   int                 `yaml:"version,omitempty"`
+	Exported  int64               `yaml:"exported,omitempty"`
+	Providers []*SCMProviderEntry `yaml:"providers"`
+	// Profiles and CurrentProfile are only restored by `prm restore`, see SCMConfig.
+	Profiles       []*Profile `yaml:"profiles,omitempty"`
+	CurrentProfile string     `yaml:"current_profile,omitempty"`
+}
+
+// SCMProviderEntry is an SCM provider to add. At most one of PAT, PATEnv and PATCmd is set, none when the PAT has been
+// redacted.
+type SCMProviderEntry struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Host        string `yaml:"host"`
+	API         string `yaml:"api,omitempty"`
+	Concurrency int    `yaml:"concurrency,omitempty"`
+	PAT         string `yaml:"pat,omitempty"`
+	PATEnv      string `yaml:"pat_env,omitempty"`
+	PATCmd      string `yaml:"pat_cmd,omitempty"`
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes the data to a temporary file next to the target and renames it over the target, so readers
+// see either the old or the new content but never a partially written file.
+func WriteFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for %s: %w", path, err)
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing temporary file for %s: %w", path, err)
+	}
+
+	// NOTE: CreateTemp already creates the file as 0600, this only guards against an unusual umask.
+	err = os.Chmod(tempPath, 0600)
+	if err != nil {
+		return fmt.Errorf("error restricting permissions of %s: %w", path, err)
+	}
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const appDirName = "prm"
+
+// ConfigDir returns the directory holding the files of prm, ie $XDG_CONFIG_HOME/prm, defaulting to ~/.config/prm as per
+// the XDG base directory spec.
+func ConfigDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	// NOTE: The spec asks for relative paths to be ignored.
+	if configHome == "" || !filepath.IsAbs(configHome) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting user home directory: %w", err)
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, appDirName), nil
+}